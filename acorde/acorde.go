@@ -0,0 +1,344 @@
+// Package acorde interpreta cifras de acordes (por exemplo, C#m7, A7(9) ou
+// G/B) e as representa de forma estruturada.
+package acorde
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Qualidades da tríade de um acorde.
+const (
+	MAIOR     = "maior"
+	MENOR     = "menor"
+	DIMINUTO  = "diminuto"
+	AUMENTADO = "aumentado"
+	QUINTA    = "quinta" // power chord: apenas fundamental e quinta.
+)
+
+// Acorde é a representação estruturada de uma cifra.
+type Acorde struct {
+	Raiz      string   `json:"raiz"`               // letra da fundamental (A a G).
+	Acidente  string   `json:"acidente,omitempty"` // # ou b.
+	Qualidade string   `json:"qualidade"`
+	Setima    string   `json:"setima,omitempty"`    // 6, 7 ou 7M.
+	Suspensao string   `json:"suspensao,omitempty"` // sus2 ou sus4.
+	Extensoes []string `json:"extensoes,omitempty"` // por exemplo, 9, b9, #11 e b5.
+	Baixo     string   `json:"baixo,omitempty"`     // nota do baixo, em acordes invertidos.
+}
+
+// Parse interpreta a cifra de um acorde. Aceita as notações usuais em
+// cifras brasileiras, como C7M, Am7(b5), A7/4, D4, Bº e E7(9+).
+func Parse(s string) (Acorde, error) {
+	original := s
+	s = strings.TrimSpace(s)
+	invalido := func() (Acorde, error) {
+		return Acorde{}, fmt.Errorf("acorde inválido: %q", original)
+	}
+
+	var a Acorde
+	var resto string
+	var ok bool
+	a.Raiz, a.Acidente, resto, ok = parseNota(s)
+	if !ok {
+		return invalido()
+	}
+	a.Qualidade = MAIOR
+
+	// Baixo: a última barra seguida de uma nota (G/B). Barras seguidas de
+	// números fazem parte do acorde (A7/4, C6/9).
+	if i := strings.LastIndex(resto, "/"); i >= 0 {
+		if raiz, acidente, r, ok := parseNota(resto[i+1:]); ok && r == "" {
+			a.Baixo = raiz + acidente
+			resto = resto[:i]
+		}
+	}
+
+	p := &parser{a: &a, s: resto}
+	if !p.parse() {
+		return invalido()
+	}
+	if a.Qualidade == QUINTA && (a.Setima != "" || a.Suspensao != "") {
+		// Sem terça, o power chord não admite sétima nem suspensão.
+		return invalido()
+	}
+	a.Extensoes = ordenaExtensoes(a.Extensoes)
+	return a, nil
+}
+
+// String retorna a cifra canônica do acorde.
+func (a Acorde) String() string {
+	var b strings.Builder
+	b.WriteString(a.Raiz)
+	b.WriteString(a.Acidente)
+	switch a.Qualidade {
+	case MENOR:
+		b.WriteString("m")
+	case DIMINUTO:
+		b.WriteString("°")
+	case AUMENTADO:
+		b.WriteString("+")
+	case QUINTA:
+		b.WriteString("5")
+	}
+	b.WriteString(a.Setima)
+	b.WriteString(a.Suspensao)
+	if len(a.Extensoes) > 0 {
+		b.WriteString("(")
+		b.WriteString(strings.Join(a.Extensoes, ","))
+		b.WriteString(")")
+	}
+	if a.Baixo != "" {
+		b.WriteString("/")
+		b.WriteString(a.Baixo)
+	}
+	return b.String()
+}
+
+// Canoniza retorna a cifra canônica de s.
+func Canoniza(s string) (string, error) {
+	a, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return a.String(), nil
+}
+
+// parseNota lê uma nota (letra e acidente opcional) do início de s.
+func parseNota(s string) (raiz, acidente, resto string, ok bool) {
+	if s == "" || s[0] < 'A' || s[0] > 'G' {
+		return "", "", s, false
+	}
+	raiz, resto = s[:1], s[1:]
+	switch {
+	case strings.HasPrefix(resto, "#"):
+		acidente, resto = "#", resto[1:]
+	case strings.HasPrefix(resto, "♯"):
+		acidente, resto = "#", resto[len("♯"):]
+	case strings.HasPrefix(resto, "♭"):
+		acidente, resto = "b", resto[len("♭"):]
+	case strings.HasPrefix(resto, "b"):
+		acidente, resto = "b", resto[1:]
+	}
+	return raiz, acidente, resto, true
+}
+
+// parser interpreta o sufixo de um acorde (tudo que vem depois da
+// fundamental e antes do baixo).
+type parser struct {
+	a *Acorde
+	s string
+	i int
+}
+
+func (p *parser) consome(prefixos ...string) bool {
+	for _, prefixo := range prefixos {
+		if strings.HasPrefix(p.s[p.i:], prefixo) {
+			p.i += len(prefixo)
+			return true
+		}
+	}
+	return false
+}
+
+func (p *parser) parse() bool {
+	// Qualidade da tríade, logo após a fundamental.
+	switch {
+	case strings.HasPrefix(p.s, "maj"):
+		// Tratado como sétima maior abaixo.
+	case p.consome("min", "m", "-"):
+		p.a.Qualidade = MENOR
+	case p.consome("dim", "º", "°", "o"):
+		p.a.Qualidade = DIMINUTO
+	case p.consome("ø"):
+		p.a.Qualidade = MENOR
+		p.a.Setima = "7"
+		p.a.Extensoes = append(p.a.Extensoes, "b5")
+	case p.consome("aug", "+"):
+		p.a.Qualidade = AUMENTADO
+	}
+	inicio := p.i
+
+	for p.i < len(p.s) {
+		switch {
+		case p.consome("maj7", "M7", "7M", "Δ7", "Δ", "7+"):
+			p.a.Setima = "7M"
+		case p.consome("maj"):
+			// Sozinho, maj indica apenas a tríade maior. Seguido de uma
+			// extensão (Cmaj9), indica também a sétima maior.
+			if p.i < len(p.s) && p.s[p.i] >= '0' && p.s[p.i] <= '9' {
+				p.a.Setima = "7M"
+			}
+		case p.consome("sus2"):
+			p.a.Suspensao = "sus2"
+		case p.consome("sus4", "sus"):
+			p.a.Suspensao = "sus4"
+		case p.consome("add"):
+			if !p.grau(false) {
+				return false
+			}
+		case p.consome("("):
+			fim := strings.Index(p.s[p.i:], ")")
+			if fim < 0 {
+				return false
+			}
+			for _, item := range strings.FieldsFunc(p.s[p.i:p.i+fim], separador) {
+				if !p.item(item) {
+					return false
+				}
+			}
+			p.i += fim + 1
+		case p.consome("/", ","):
+			// Separadores entre graus, como em A7/4 e C6/9. Precisam ser
+			// seguidos de um grau: E/ e C//E são inválidos.
+			if !p.grau(true) {
+				return false
+			}
+		case p.consome("M"):
+			// Maior, explícito.
+		default:
+			if p.i == inicio && p.a.Qualidade == MAIOR && p.consome("5") {
+				// Power chord, com extensões opcionais entre parênteses: C5 e
+				// C5(9).
+				if p.i == len(p.s) || p.s[p.i] == '(' {
+					p.a.Qualidade = QUINTA
+					continue
+				}
+				p.i--
+			}
+			if !p.grau(true) {
+				return false
+			}
+		}
+	}
+	return true
+}
+
+func separador(r rune) bool {
+	return r == ',' || r == '/' || r == '.' || r == ' '
+}
+
+// item interpreta um grau isolado, como os que aparecem entre parênteses.
+func (p *parser) item(item string) bool {
+	sub := &parser{a: p.a, s: item}
+	for sub.i < len(sub.s) {
+		if sub.consome("maj7", "7M", "M7", "7+") {
+			p.a.Setima = "7M"
+			continue
+		}
+		sub.consome("add")
+		if !sub.grau(false) {
+			return false
+		}
+	}
+	return true
+}
+
+// grau lê um grau (com acidente opcional) e o adiciona ao acorde.
+func (p *parser) grau(semParenteses bool) bool {
+	acidente := ""
+	switch {
+	case p.consome("#", "+", "♯"):
+		acidente = "#"
+	case p.consome("b", "-", "♭"):
+		acidente = "b"
+	}
+	j := p.i
+	for j < len(p.s) && p.s[j] >= '0' && p.s[j] <= '9' {
+		j++
+	}
+	if j == p.i {
+		return false
+	}
+	n, _ := strconv.Atoi(p.s[p.i:j])
+	p.i = j
+	// Acidente posfixado, como em 9+ e 5-.
+	if acidente == "" && !(semParenteses && n == 7) {
+		switch {
+		case p.consome("+"):
+			acidente = "#"
+		case p.consome("-"):
+			acidente = "b"
+		}
+	}
+	return p.a.adicionaGrau(n, acidente)
+}
+
+func (a *Acorde) adicionaGrau(n int, acidente string) bool {
+	if acidente != "" {
+		switch n {
+		case 4:
+			n = 11
+		case 6:
+			n = 13
+		case 5, 9, 11, 13:
+		default:
+			return false
+		}
+		a.Extensoes = append(a.Extensoes, acidente+strconv.Itoa(n))
+		return true
+	}
+	switch n {
+	case 2:
+		a.Suspensao = "sus2"
+	case 4:
+		a.Suspensao = "sus4"
+	case 5:
+		// A quinta justa já faz parte da tríade.
+	case 6:
+		if a.Setima == "" {
+			a.Setima = "6"
+		} else {
+			a.Extensoes = append(a.Extensoes, "13")
+		}
+	case 7:
+		switch a.Setima {
+		case "6":
+			a.Extensoes = append(a.Extensoes, "13")
+			a.Setima = "7"
+		case "":
+			a.Setima = "7"
+		}
+	case 9, 11, 13:
+		a.Extensoes = append(a.Extensoes, strconv.Itoa(n))
+	default:
+		return false
+	}
+	return true
+}
+
+// ordenaExtensoes remove extensões repetidas e as ordena pelo grau.
+func ordenaExtensoes(extensoes []string) []string {
+	if len(extensoes) == 0 {
+		return nil
+	}
+	vistas := make(map[string]bool)
+	var unicas []string
+	for _, e := range extensoes {
+		if !vistas[e] {
+			vistas[e] = true
+			unicas = append(unicas, e)
+		}
+	}
+	grau := func(e string) (int, int) {
+		n, _ := strconv.Atoi(strings.TrimLeft(e, "#b"))
+		switch e[0] {
+		case 'b':
+			return n, 0
+		case '#':
+			return n, 2
+		}
+		return n, 1
+	}
+	sort.Slice(unicas, func(i, j int) bool {
+		ni, ai := grau(unicas[i])
+		nj, aj := grau(unicas[j])
+		if ni != nj {
+			return ni < nj
+		}
+		return ai < aj
+	})
+	return unicas
+}
@@ -0,0 +1,133 @@
+package acorde
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	for _, c := range []struct {
+		cifra string
+		want  Acorde
+	}{
+		{"C", Acorde{Raiz: "C", Qualidade: MAIOR}},
+		{"C#m7", Acorde{Raiz: "C", Acidente: "#", Qualidade: MENOR, Setima: "7"}},
+		{"Bbm", Acorde{Raiz: "B", Acidente: "b", Qualidade: MENOR}},
+		{"E♭", Acorde{Raiz: "E", Acidente: "b", Qualidade: MAIOR}},
+		{"Cmaj", Acorde{Raiz: "C", Qualidade: MAIOR}},
+		{"CM", Acorde{Raiz: "C", Qualidade: MAIOR}},
+		{"Cmaj7", Acorde{Raiz: "C", Qualidade: MAIOR, Setima: "7M"}},
+		{"Cmaj9", Acorde{Raiz: "C", Qualidade: MAIOR, Setima: "7M", Extensoes: []string{"9"}}},
+		{"C7M", Acorde{Raiz: "C", Qualidade: MAIOR, Setima: "7M"}},
+		{"CΔ", Acorde{Raiz: "C", Qualidade: MAIOR, Setima: "7M"}},
+		{"Cm7M", Acorde{Raiz: "C", Qualidade: MENOR, Setima: "7M"}},
+		{"C5", Acorde{Raiz: "C", Qualidade: QUINTA}},
+		{"C5(9)", Acorde{Raiz: "C", Qualidade: QUINTA, Extensoes: []string{"9"}}},
+		{"C(5)", Acorde{Raiz: "C", Qualidade: MAIOR}},
+		{"C7(5-)", Acorde{Raiz: "C", Qualidade: MAIOR, Setima: "7", Extensoes: []string{"b5"}}},
+		{"Am7(b5)", Acorde{Raiz: "A", Qualidade: MENOR, Setima: "7", Extensoes: []string{"b5"}}},
+		{"Bø", Acorde{Raiz: "B", Qualidade: MENOR, Setima: "7", Extensoes: []string{"b5"}}},
+		{"Bº", Acorde{Raiz: "B", Qualidade: DIMINUTO}},
+		{"Bdim7", Acorde{Raiz: "B", Qualidade: DIMINUTO, Setima: "7"}},
+		{"C+", Acorde{Raiz: "C", Qualidade: AUMENTADO}},
+		{"A7/4", Acorde{Raiz: "A", Qualidade: MAIOR, Setima: "7", Suspensao: "sus4"}},
+		{"D4", Acorde{Raiz: "D", Qualidade: MAIOR, Suspensao: "sus4"}},
+		{"Dsus", Acorde{Raiz: "D", Qualidade: MAIOR, Suspensao: "sus4"}},
+		{"Asus2", Acorde{Raiz: "A", Qualidade: MAIOR, Suspensao: "sus2"}},
+		{"C6/9", Acorde{Raiz: "C", Qualidade: MAIOR, Setima: "6", Extensoes: []string{"9"}}},
+		{"Cadd9", Acorde{Raiz: "C", Qualidade: MAIOR, Extensoes: []string{"9"}}},
+		{"E7(9+)", Acorde{Raiz: "E", Qualidade: MAIOR, Setima: "7", Extensoes: []string{"#9"}}},
+		{"G7(13,b9)", Acorde{Raiz: "G", Qualidade: MAIOR, Setima: "7", Extensoes: []string{"b9", "13"}}},
+		{"G/B", Acorde{Raiz: "G", Qualidade: MAIOR, Baixo: "B"}},
+		{"D/F#", Acorde{Raiz: "D", Qualidade: MAIOR, Baixo: "F#"}},
+		{"Am7/G", Acorde{Raiz: "A", Qualidade: MENOR, Setima: "7", Baixo: "G"}},
+		{" Em ", Acorde{Raiz: "E", Qualidade: MENOR}},
+	} {
+		got, err := Parse(c.cifra)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", c.cifra, err)
+			continue
+		}
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("Parse(%q) = %+v, want %+v", c.cifra, got, c.want)
+		}
+	}
+}
+
+func TestParseInvalido(t *testing.T) {
+	for _, cifra := range []string{
+		"", "H", "c", "x", "|", "Refrão:",
+		"E/", "C//E", "C/", "A7/", "C,",
+		"C(9", "C7(x)", "C8", "C5(7)", "Db♭",
+	} {
+		if a, err := Parse(cifra); err == nil {
+			t.Errorf("Parse(%q) = %+v, want erro", cifra, a)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	for _, c := range []struct {
+		cifra, want string
+	}{
+		{"C", "C"},
+		{"Cmaj", "C"},
+		{"Cmaj7", "C7M"},
+		{"C5(9)", "C5(9)"},
+		{"Bº", "B°"},
+		{"Bø", "Bm7(b5)"},
+		{"A7/4", "A7sus4"},
+		{"G7(13,b9)", "G7(b9,13)"},
+		{"C6/9", "C6(9)"},
+		{"E7(9+)", "E7(#9)"},
+		{"D/F#", "D/F#"},
+	} {
+		got, err := Canoniza(c.cifra)
+		if err != nil || got != c.want {
+			t.Errorf("Canoniza(%q) = %q, %v, want %q", c.cifra, got, err, c.want)
+		}
+	}
+}
+
+// A cifra canônica de um acorde deve ser interpretada como o mesmo acorde.
+func TestParseString(t *testing.T) {
+	for _, cifra := range []string{
+		"C", "Cmaj", "Cmaj7", "Cmaj9", "C#m7", "Bbm6", "C5", "C5(9)", "Bº",
+		"Bdim7", "Bø", "C+", "C+7", "A7/4", "Asus2", "C6/9", "Cadd9", "C7(5-)",
+		"E7(9+)", "G7(13,b9)", "Am7M", "F#m7(b5)/E", "G/B", "D/F#", "Ab7(#11)",
+		"Dm(9)", "A7(b13)", "C9", "C11", "C13",
+	} {
+		a, err := Parse(cifra)
+		if err != nil {
+			t.Errorf("Parse(%q): %v", cifra, err)
+			continue
+		}
+		b, err := Parse(a.String())
+		if err != nil {
+			t.Errorf("Parse(%q): %v (canônica de %q)", a.String(), err, cifra)
+			continue
+		}
+		if !reflect.DeepEqual(a, b) {
+			t.Errorf("Parse(%q) = %+v, Parse(%q) = %+v", cifra, a, a.String(), b)
+		}
+	}
+}
+
+func TestChaveEnarmonica(t *testing.T) {
+	for _, c := range []struct {
+		cifra, want string
+	}{
+		{"Bb7", "A#7"},
+		{"A#7", "A#7"},
+		{"Dbm/Ab", "C#m/G#"},
+		{"Gb", "F#"},
+		{"Cb", "B"},
+		{"E#", "F"},
+		{"G", "G"},
+	} {
+		got, err := ChaveEnarmonica(c.cifra)
+		if err != nil || got != c.want {
+			t.Errorf("ChaveEnarmonica(%q) = %q, %v, want %q", c.cifra, got, err, c.want)
+		}
+	}
+}
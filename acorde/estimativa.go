@@ -0,0 +1,180 @@
+package acorde
+
+import "sort"
+
+// Estimativa é a pontuação de um tom candidato para uma sequência de acordes.
+type Estimativa struct {
+	Tom       Tom     `json:"tom"`
+	Pontuacao float64 `json:"pontuacao"`
+}
+
+// funcao descreve um acorde diatônico de um tom: o intervalo da fundamental
+// em relação à tônica, a qualidade da tríade e o peso da função na
+// caracterização do tom.
+type funcao struct {
+	intervalo int
+	qualidade string
+	peso      float64
+}
+
+var (
+	funcoesMaior = []funcao{
+		{0, MAIOR, 3}, {2, MENOR, 1}, {4, MENOR, 1}, {5, MAIOR, 2},
+		{7, MAIOR, 2.5}, {9, MENOR, 1.5}, {11, DIMINUTO, 1},
+	}
+	// Tom menor considerando as escalas natural e harmônica.
+	funcoesMenor = []funcao{
+		{0, MENOR, 3}, {2, DIMINUTO, 1}, {3, MAIOR, 1.5}, {5, MENOR, 2},
+		{7, MAIOR, 2.5}, {7, MENOR, 1}, {8, MAIOR, 1.5}, {10, MAIOR, 1}, {11, DIMINUTO, 1},
+	}
+)
+
+const (
+	// Penalidade para acordes que não pertencem ao tom.
+	penalidadeCromatico = -1
+	// Bônus para músicas que começam e terminam na tônica.
+	bonusInicio = 0.5
+	bonusFim    = 0.75
+	// Bônus para a dominante com sétima.
+	bonusDominante = 0.5
+	// Diferença de pontuação abaixo da qual um tom e seu relativo (C e Am,
+	// por exemplo), que compartilham os acordes diatônicos, são considerados
+	// empatados.
+	empateRelativo = 0.25
+)
+
+// EstimaTons pontua os 24 tons maiores e menores para a sequência de
+// acordes passada, levando em conta a frequência de cada acorde e o
+// primeiro e último acordes. Retorna as estimativas ordenadas da maior para
+// a menor pontuação.
+func EstimaTons(cifra []Acorde) []Estimativa {
+	var estimativas []Estimativa
+	if len(cifra) == 0 {
+		return estimativas
+	}
+	for tonica := 0; tonica < 12; tonica++ {
+		for _, menor := range []bool{false, true} {
+			funcoes := funcoesMaior
+			if menor {
+				funcoes = funcoesMenor
+			}
+			soma := 0.0
+			for _, a := range cifra {
+				soma += pesoNoTom(a, tonica, funcoes)
+			}
+			pontuacao := soma / float64(len(cifra))
+			if ehTonica(cifra[0], tonica, menor) {
+				pontuacao += bonusInicio
+			}
+			if ehTonica(cifra[len(cifra)-1], tonica, menor) {
+				pontuacao += bonusFim
+			}
+			estimativas = append(estimativas, Estimativa{novoTom(tonica, menor), pontuacao})
+		}
+	}
+	sort.SliceStable(estimativas, func(i, j int) bool {
+		return estimativas[i].Pontuacao > estimativas[j].Pontuacao
+	})
+	return estimativas
+}
+
+// EstimaTom retorna o tom mais provável para a sequência de acordes e a
+// confiança da estimativa (entre 0 e 1). Um tom e seu relativo pontuam quase
+// igual, pois compartilham os acordes diatônicos: quando empatados, vence o
+// tom cuja tônica é o último acorde ou, em seguida, o primeiro. A confiança
+// é calculada a partir da diferença para o melhor tom que não é o relativo
+// do escolhido. Retorna falso caso a sequência seja vazia.
+func EstimaTom(cifra []Acorde) (Tom, float64, bool) {
+	estimativas := EstimaTons(cifra)
+	if len(estimativas) == 0 {
+		return Tom{}, 0, false
+	}
+	melhor := estimativas[0]
+	for _, e := range estimativas[1:] {
+		if relativos(e.Tom, melhor.Tom) {
+			if melhor.Pontuacao-e.Pontuacao < empateRelativo && desempata(cifra, e.Tom, melhor.Tom) {
+				melhor = e
+			}
+			break
+		}
+	}
+	if melhor.Pontuacao <= 0 {
+		return melhor.Tom, 0, true
+	}
+	concorrente := 0.0
+	for _, e := range estimativas {
+		if e.Tom != melhor.Tom && !relativos(e.Tom, melhor.Tom) {
+			concorrente = e.Pontuacao
+			break
+		}
+	}
+	confianca := (melhor.Pontuacao - concorrente) / melhor.Pontuacao
+	if confianca > 1 {
+		confianca = 1
+	}
+	return melhor.Tom, confianca, true
+}
+
+// relativos indica se os tons são relativos (C e Am, por exemplo).
+func relativos(a, b Tom) bool {
+	return a.Menor != b.Menor && a.Relativo().Altura() == b.Altura()
+}
+
+// desempata indica se o tom a deve ser preferido ao seu relativo b: a
+// cifra termina na tônica de a ou, não terminando na tônica de b, começa
+// na tônica de a.
+func desempata(cifra []Acorde, a, b Tom) bool {
+	ultimo := cifra[len(cifra)-1]
+	switch {
+	case ehTonica(ultimo, a.Altura(), a.Menor):
+		return true
+	case ehTonica(ultimo, b.Altura(), b.Menor):
+		return false
+	}
+	return ehTonica(cifra[0], a.Altura(), a.Menor)
+}
+
+func pesoNoTom(a Acorde, tonica int, funcoes []funcao) float64 {
+	intervalo := ((a.Altura()-tonica)%12 + 12) % 12
+	qualidade := a.Qualidade
+	if qualidade == MENOR && a.Setima == "7" && contem(a.Extensoes, "b5") {
+		// Meio diminuto.
+		qualidade = DIMINUTO
+	}
+	for _, f := range funcoes {
+		if f.intervalo != intervalo {
+			continue
+		}
+		switch {
+		case f.qualidade == qualidade:
+			peso := f.peso
+			if intervalo == 7 && qualidade == MAIOR && a.Setima == "7" {
+				peso += bonusDominante
+			}
+			return peso
+		case qualidade == QUINTA || a.Suspensao != "":
+			// Sem a terça, apenas a fundamental indica o tom.
+			return f.peso / 2
+		}
+	}
+	return penalidadeCromatico
+}
+
+func ehTonica(a Acorde, tonica int, menor bool) bool {
+	if a.Altura() != tonica {
+		return false
+	}
+	if menor {
+		return a.Qualidade == MENOR
+	}
+	return a.Qualidade == MAIOR
+}
+
+func contem(lista []string, s string) bool {
+	for _, v := range lista {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,72 @@
+package acorde
+
+import "testing"
+
+func TestEstimaTom(t *testing.T) {
+	for _, c := range []struct {
+		cifra     []string
+		want      string
+		confianca float64 // confiança mínima.
+	}{
+		{[]string{"C", "G", "Am", "F", "C"}, "C", 0.3},
+		{[]string{"G", "D", "Em", "C"}, "G", 0.2},
+		{[]string{"Am", "Dm", "E7", "Am"}, "Am", 0.3},
+		{[]string{"F", "Bb", "C7", "F"}, "F", 0.3},
+		// Progressões que pontuam igual no tom e no relativo: vence o tom
+		// cuja tônica é o último acorde ou, em seguida, o primeiro.
+		{[]string{"Am", "F", "C", "G"}, "Am", 0.1},
+		{[]string{"Em", "C", "G", "D"}, "Em", 0.1},
+		{[]string{"Bm", "G", "D", "A"}, "Bm", 0.1},
+		{[]string{"Am", "F", "C", "G", "C"}, "C", 0.2},
+		{[]string{"C", "Am", "F", "G", "Am"}, "Am", 0.2},
+	} {
+		tom, confianca, ok := EstimaTom(cifraTeste(t, c.cifra...))
+		if !ok {
+			t.Errorf("EstimaTom(%v): sem estimativa", c.cifra)
+			continue
+		}
+		if tom.String() != c.want {
+			t.Errorf("EstimaTom(%v) = %s, want %s", c.cifra, tom, c.want)
+		}
+		if confianca < c.confianca || confianca > 1 {
+			t.Errorf("EstimaTom(%v): confiança %v, want entre %v e 1", c.cifra, confianca, c.confianca)
+		}
+	}
+	if _, _, ok := EstimaTom(nil); ok {
+		t.Error("EstimaTom(nil): want falso")
+	}
+}
+
+func TestEstimaTons(t *testing.T) {
+	estimativas := EstimaTons(cifraTeste(t, "C", "F", "G7", "C"))
+	if len(estimativas) != 24 {
+		t.Fatalf("len(EstimaTons) = %d, want 24", len(estimativas))
+	}
+	if estimativas[0].Tom.String() != "C" {
+		t.Errorf("melhor estimativa = %s, want C", estimativas[0].Tom)
+	}
+	for i := 1; i < len(estimativas); i++ {
+		if estimativas[i].Pontuacao > estimativas[i-1].Pontuacao {
+			t.Fatalf("estimativas fora de ordem: %v", estimativas)
+		}
+	}
+}
+
+func TestRelativos(t *testing.T) {
+	for _, c := range []struct {
+		a, b string
+		want bool
+	}{
+		{"C", "Am", true},
+		{"Am", "C", true},
+		{"Eb", "Cm", true},
+		{"F#", "D#m", true},
+		{"C", "Cm", false},
+		{"C", "G", false},
+		{"Am", "Em", false},
+	} {
+		if got := relativos(tomTeste(t, c.a), tomTeste(t, c.b)); got != c.want {
+			t.Errorf("relativos(%s, %s) = %v, want %v", c.a, c.b, got, c.want)
+		}
+	}
+}
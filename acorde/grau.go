@@ -0,0 +1,109 @@
+package acorde
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Graus da escala cromática, em algarismos romanos, a partir da tônica.
+var numerais = []string{"I", "bII", "II", "bIII", "III", "IV", "#IV", "V", "bVI", "VI", "bVII", "VII"}
+
+// Grau retorna a função do acorde em relação à tônica passada (classe de
+// altura), em algarismos romanos. Acordes menores e diminutos são escritos
+// em minúsculas. Exemplos, em relação a C: G7 é V7, Am é vi, Bb é bVII e
+// G/B é V/VII.
+func (a Acorde) Grau(tonica int) string {
+	var b strings.Builder
+	numeral := numerais[((a.Altura()-tonica)%12+12)%12]
+	switch a.Qualidade {
+	case MENOR, DIMINUTO:
+		numeral = strings.ToLower(numeral)
+	}
+	b.WriteString(numeral)
+	switch a.Qualidade {
+	case DIMINUTO:
+		b.WriteString("°")
+	case AUMENTADO:
+		b.WriteString("+")
+	case QUINTA:
+		b.WriteString("5")
+	}
+	b.WriteString(a.Setima)
+	b.WriteString(a.Suspensao)
+	if len(a.Extensoes) > 0 {
+		b.WriteString("(")
+		b.WriteString(strings.Join(a.Extensoes, ","))
+		b.WriteString(")")
+	}
+	if baixo, ok := a.AlturaBaixo(); ok {
+		b.WriteString("/")
+		b.WriteString(numerais[((baixo-tonica)%12+12)%12])
+	}
+	return b.String()
+}
+
+// Numerais sem acidente e seus intervalos em relação à tônica, dos mais
+// longos aos mais curtos, na ordem em que são procurados.
+var numeraisNaturais = []struct {
+	numeral string
+	altura  int
+}{
+	{"VII", 11}, {"III", 4}, {"VI", 9}, {"IV", 5}, {"II", 2}, {"V", 7}, {"I", 0},
+}
+
+// ParseGrau interpreta um grau escrito como em Grau (por exemplo, V7, vi,
+// bVII ou V/VII) e retorna o acorde correspondente em relação a C. Numerais
+// em minúsculas indicam acordes menores ou, seguidos de °, diminutos. Caso
+// menor seja verdadeiro, os graus III, VI e VII em maiúsculas e sem acidente
+// se referem à escala menor natural (bIII, bVI e bVII).
+func ParseGrau(s string, menor bool) (Acorde, error) {
+	invalido := func() (Acorde, error) {
+		return Acorde{}, fmt.Errorf("grau inválido: %q", s)
+	}
+	altura, minusculo, resto, ok := parseNumeral(s, menor)
+	if !ok {
+		return invalido()
+	}
+	var baixo string
+	if i := strings.LastIndex(resto, "/"); i >= 0 {
+		b, _, r, ok := parseNumeral(resto[i+1:], menor)
+		if !ok || r != "" {
+			return invalido()
+		}
+		baixo = "/" + NomeNota(b, true)
+		resto = resto[:i]
+	}
+	qualidade := ""
+	if minusculo && !strings.HasPrefix(resto, "°") {
+		qualidade = "m"
+	}
+	a, err := Parse(NomeNota(altura, true) + qualidade + resto + baixo)
+	if err != nil {
+		return invalido()
+	}
+	return a, nil
+}
+
+// parseNumeral separa o numeral do início de s, retornando seu intervalo em
+// relação à tônica, se está em minúsculas e o restante de s.
+func parseNumeral(s string, menor bool) (altura int, minusculo bool, resto string, ok bool) {
+	acidente := 0
+	switch {
+	case strings.HasPrefix(s, "b"):
+		acidente, s = -1, s[1:]
+	case strings.HasPrefix(s, "#"):
+		acidente, s = 1, s[1:]
+	}
+	for _, n := range numeraisNaturais {
+		maiusculo := strings.HasPrefix(s, n.numeral)
+		if !maiusculo && !strings.HasPrefix(s, strings.ToLower(n.numeral)) {
+			continue
+		}
+		altura = n.altura + acidente
+		if menor && maiusculo && acidente == 0 && (n.altura == 4 || n.altura == 9 || n.altura == 11) {
+			altura--
+		}
+		return (altura + 12) % 12, !maiusculo, s[len(n.numeral):], true
+	}
+	return 0, false, s, false
+}
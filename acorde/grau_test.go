@@ -0,0 +1,60 @@
+package acorde
+
+import "testing"
+
+func TestGrau(t *testing.T) {
+	for _, c := range []struct {
+		cifra  string
+		tonica int
+		want   string
+	}{
+		{"G7", 0, "V7"},
+		{"Am", 0, "vi"},
+		{"Bb", 0, "bVII"},
+		{"G/B", 0, "V/VII"},
+		{"B°", 0, "vii°"},
+		{"E", 9, "V"},
+		{"C", 9, "bIII"},
+		{"Bm7(b5)", 9, "ii7(b5)"},
+		{"D5", 2, "I5"},
+	} {
+		a, err := Parse(c.cifra)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := a.Grau(c.tonica); got != c.want {
+			t.Errorf("Parse(%q).Grau(%d) = %q, want %q", c.cifra, c.tonica, got, c.want)
+		}
+	}
+}
+
+func TestParseGrau(t *testing.T) {
+	for _, c := range []struct {
+		grau  string
+		menor bool
+		want  string
+	}{
+		{"V7", false, "G7"},
+		{"vi", false, "Am"},
+		{"bVII", false, "Bb"},
+		{"V/VII", false, "G/B"},
+		{"vii°", false, "B°"},
+		{"VI", true, "Ab"},
+		{"VI", false, "A"},
+		{"iv", true, "Fm"},
+	} {
+		a, err := ParseGrau(c.grau, c.menor)
+		if err != nil {
+			t.Errorf("ParseGrau(%q, %v): %v", c.grau, c.menor, err)
+			continue
+		}
+		if got := a.String(); got != c.want {
+			t.Errorf("ParseGrau(%q, %v) = %q, want %q", c.grau, c.menor, got, c.want)
+		}
+	}
+	for _, grau := range []string{"", "X", "V/", "V/H"} {
+		if _, err := ParseGrau(grau, false); err == nil {
+			t.Errorf("ParseGrau(%q): want erro", grau)
+		}
+	}
+}
@@ -0,0 +1,77 @@
+package acorde
+
+import "fmt"
+
+var alturas = map[string]int{"C": 0, "D": 2, "E": 4, "F": 5, "G": 7, "A": 9, "B": 11}
+
+// Nomes das doze notas, grafadas com sustenidos e com bemóis.
+var (
+	sustenidos = []string{"C", "C#", "D", "D#", "E", "F", "F#", "G", "G#", "A", "A#", "B"}
+	bemois     = []string{"C", "Db", "D", "Eb", "E", "F", "Gb", "G", "Ab", "A", "Bb", "B"}
+)
+
+// Altura retorna a classe de altura (0 = C, 1 = C#/Db, ..., 11 = B) da nota.
+func Altura(nota string) (int, error) {
+	raiz, acidente, resto, ok := parseNota(nota)
+	if !ok || resto != "" {
+		return 0, fmt.Errorf("nota inválida: %q", nota)
+	}
+	return altura(raiz, acidente), nil
+}
+
+func altura(raiz, acidente string) int {
+	a := alturas[raiz]
+	switch acidente {
+	case "#":
+		a++
+	case "b":
+		a--
+	}
+	return (a + 12) % 12
+}
+
+// NomeNota retorna o nome da nota com a classe de altura passada, grafada com
+// bemóis ou sustenidos.
+func NomeNota(altura int, bemol bool) string {
+	altura = ((altura % 12) + 12) % 12
+	if bemol {
+		return bemois[altura]
+	}
+	return sustenidos[altura]
+}
+
+// Altura retorna a classe de altura da fundamental do acorde.
+func (a Acorde) Altura() int {
+	return altura(a.Raiz, a.Acidente)
+}
+
+// AlturaBaixo retorna a classe de altura do baixo, caso o acorde seja
+// invertido.
+func (a Acorde) AlturaBaixo() (int, bool) {
+	if a.Baixo == "" {
+		return 0, false
+	}
+	raiz, acidente, _, _ := parseNota(a.Baixo)
+	return altura(raiz, acidente), true
+}
+
+// Grafa retorna o acorde com fundamental e baixo grafados com bemóis ou
+// sustenidos.
+func (a Acorde) Grafa(bemol bool) Acorde {
+	return a.Transpoe(0, bemol)
+}
+
+// Enarmonico retorna a forma canônica do acorde por classe de altura, de modo
+// que grafias enarmônicas (A#7 e Bb7, por exemplo) resultem no mesmo acorde.
+func (a Acorde) Enarmonico() Acorde {
+	return a.Grafa(false)
+}
+
+// ChaveEnarmonica retorna a cifra da forma enarmônica canônica de s.
+func ChaveEnarmonica(s string) (string, error) {
+	a, err := Parse(s)
+	if err != nil {
+		return "", err
+	}
+	return a.Enarmonico().String(), nil
+}
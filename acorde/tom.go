@@ -0,0 +1,156 @@
+package acorde
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Tom representa a tonalidade de uma música, como C, F#m ou Bb.
+type Tom struct {
+	Tonica string // nota, com acidente opcional.
+	Menor  bool
+}
+
+// ParseTom interpreta um tom no formato usado pelo dataset (C, Am, F#m, Bb).
+func ParseTom(s string) (Tom, error) {
+	s = strings.TrimSpace(s)
+	raiz, acidente, resto, ok := parseNota(s)
+	if !ok || (resto != "" && resto != "m") {
+		return Tom{}, fmt.Errorf("tom inválido: %q", s)
+	}
+	return Tom{Tonica: raiz + acidente, Menor: resto == "m"}, nil
+}
+
+func (t Tom) String() string {
+	if t.Menor {
+		return t.Tonica + "m"
+	}
+	return t.Tonica
+}
+
+// MarshalText permite que o tom seja serializado (em JSON, por exemplo) no
+// mesmo formato aceito por ParseTom.
+func (t Tom) MarshalText() ([]byte, error) {
+	return []byte(t.String()), nil
+}
+
+// Altura retorna a classe de altura da tônica.
+func (t Tom) Altura() int {
+	raiz, acidente, _, _ := parseNota(t.Tonica)
+	return altura(raiz, acidente)
+}
+
+// Tônicas usuais de cada classe de altura, nos tons maiores e menores.
+var (
+	tonicasMaiores = []string{"C", "Db", "D", "Eb", "E", "F", "F#", "G", "Ab", "A", "Bb", "B"}
+	tonicasMenores = []string{"C", "C#", "D", "Eb", "E", "F", "F#", "G", "G#", "A", "Bb", "B"}
+)
+
+// tonsBemois contém os tons de tônica natural escritos com bemóis.
+var tonsBemois = map[Tom]bool{
+	{"F", false}: true, {"D", true}: true, {"G", true}: true, {"C", true}: true, {"F", true}: true,
+}
+
+// UsaBemois indica se as notas do tom são escritas com bemóis (caso
+// contrário, com sustenidos). Tons cuja tônica tem acidente seguem o
+// acidente: Gb e Ebm usam bemóis, F# e D#m usam sustenidos. O tom vazio
+// (sem tônica) usa sustenidos.
+func (t Tom) UsaBemois() bool {
+	if t.Tonica == "" {
+		return false
+	}
+	switch t.Tonica[1:] {
+	case "b":
+		return true
+	case "#":
+		return false
+	}
+	return tonsBemois[t]
+}
+
+// Transpoe retorna o tom transposto pelo número de semitons passado, com a
+// tônica usual da classe de altura resultante (Eb e não D#, por exemplo).
+func (t Tom) Transpoe(semitons int) Tom {
+	return novoTom(t.Altura()+semitons, t.Menor)
+}
+
+// Relativo retorna o tom relativo, que compartilha a armadura (Am para C e C
+// para Am).
+func (t Tom) Relativo() Tom {
+	if t.Menor {
+		return novoTom(t.Altura()+3, false)
+	}
+	return novoTom(t.Altura()-3, true)
+}
+
+// novoTom retorna o tom com a tônica passada (classe de altura), grafada da
+// forma usual.
+func novoTom(tonica int, menor bool) Tom {
+	tonica = ((tonica % 12) + 12) % 12
+	if menor {
+		return Tom{Tonica: tonicasMenores[tonica], Menor: true}
+	}
+	return Tom{Tonica: tonicasMaiores[tonica]}
+}
+
+// TranspoeNoTom retorna o acorde, escrito no tom de, transposto para o tom
+// para. As notas mantêm o intervalo em relação à tônica: a letra avança
+// tantas notas quanto a tônica e o acidente completa a distância em
+// semitons. Assim, A7/C# em A resulta em Eb7/G em Eb, e C# continua C# em
+// Dm. Notas que exigiriam acidentes dobrados seguem a armadura do tom para.
+func (a Acorde) TranspoeNoTom(de, para Tom) Acorde {
+	letras := strings.Index(ordemLetras, para.Tonica[:1]) - strings.Index(ordemLetras, de.Tonica[:1])
+	semitons := para.Altura() - de.Altura()
+	bemol := para.UsaBemois()
+	a.Raiz, a.Acidente = transpoeNota(a.Raiz, a.Acidente, letras, semitons, bemol)
+	if a.Baixo != "" {
+		raiz, acidente, _, _ := parseNota(a.Baixo)
+		raiz, acidente = transpoeNota(raiz, acidente, letras, semitons, bemol)
+		a.Baixo = raiz + acidente
+	}
+	return a
+}
+
+// Letras das notas, na ordem da escala.
+const ordemLetras = "CDEFGAB"
+
+// transpoeNota avança a letra da nota pelo número de letras passado e escolhe
+// o acidente que a transpõe pelo número de semitons passado.
+func transpoeNota(raiz, acidente string, letras, semitons int, bemol bool) (string, string) {
+	alvo := ((altura(raiz, acidente)+semitons)%12 + 12) % 12
+	i := ((strings.Index(ordemLetras, raiz)+letras)%7 + 7) % 7
+	letra := ordemLetras[i : i+1]
+	switch Intervalo(alturas[letra], alvo) {
+	case 0:
+		return letra, ""
+	case 1:
+		return letra, "#"
+	case -1:
+		return letra, "b"
+	}
+	return separaNota(NomeNota(alvo, bemol))
+}
+
+// Transpoe retorna o acorde transposto pelo número de semitons passado, com
+// fundamental e baixo grafados com bemóis ou sustenidos.
+func (a Acorde) Transpoe(semitons int, bemol bool) Acorde {
+	a.Raiz, a.Acidente = separaNota(NomeNota(a.Altura()+semitons, bemol))
+	if b, ok := a.AlturaBaixo(); ok {
+		a.Baixo = NomeNota(b+semitons, bemol)
+	}
+	return a
+}
+
+// Intervalo retorna o menor deslocamento, em semitons, que leva da classe de
+// altura de para a classe de altura para (entre -5 e 6).
+func Intervalo(de, para int) int {
+	d := ((para-de)%12 + 12) % 12
+	if d > 6 {
+		d -= 12
+	}
+	return d
+}
+
+func separaNota(nota string) (raiz, acidente string) {
+	return nota[:1], nota[1:]
+}
@@ -0,0 +1,130 @@
+package acorde
+
+import "testing"
+
+func tomTeste(t *testing.T, s string) Tom {
+	t.Helper()
+	tom, err := ParseTom(s)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return tom
+}
+
+func TestParseTom(t *testing.T) {
+	for _, s := range []string{"C", "Am", "F#m", "Bb", " Eb "} {
+		if _, err := ParseTom(s); err != nil {
+			t.Errorf("ParseTom(%q): %v", s, err)
+		}
+	}
+	for _, s := range []string{"", "H", "Cmaj", "Am7", "C#mm"} {
+		if _, err := ParseTom(s); err == nil {
+			t.Errorf("ParseTom(%q): want erro", s)
+		}
+	}
+}
+
+func TestUsaBemois(t *testing.T) {
+	for tom, want := range map[string]bool{
+		"C": false, "G": false, "B": false, "F#": false, "C#": false,
+		"F": true, "Bb": true, "Eb": true, "Ab": true, "Db": true, "Gb": true,
+		"Am": false, "Em": false, "F#m": false, "D#m": false, "G#m": false,
+		"Dm": true, "Gm": true, "Cm": true, "Fm": true, "Bbm": true, "Ebm": true,
+	} {
+		if got := tomTeste(t, tom).UsaBemois(); got != want {
+			t.Errorf("%s.UsaBemois() = %v, want %v", tom, got, want)
+		}
+	}
+	if (Tom{}).UsaBemois() {
+		t.Error("Tom{}.UsaBemois() = true, want false")
+	}
+}
+
+func TestRelativo(t *testing.T) {
+	for tom, want := range map[string]string{
+		"C": "Am", "Am": "C", "Eb": "Cm", "F#m": "A", "D#m": "F#", "Gb": "Ebm",
+	} {
+		if got := tomTeste(t, tom).Relativo().String(); got != want {
+			t.Errorf("%s.Relativo() = %s, want %s", tom, got, want)
+		}
+	}
+}
+
+func TestTomTranspoe(t *testing.T) {
+	for _, c := range []struct {
+		tom      string
+		semitons int
+		want     string
+	}{
+		{"C", 2, "D"},
+		{"C", 3, "Eb"},
+		{"C", 6, "F#"},
+		{"C", -1, "B"},
+		{"A#", 0, "Bb"},
+		{"Am", 1, "Bbm"},
+		{"Cm", 3, "Ebm"},
+		{"Em", -1, "Ebm"},
+		{"Am", -1, "G#m"},
+	} {
+		if got := tomTeste(t, c.tom).Transpoe(c.semitons).String(); got != c.want {
+			t.Errorf("%s.Transpoe(%d) = %s, want %s", c.tom, c.semitons, got, c.want)
+		}
+	}
+}
+
+func TestAcordeTranspoe(t *testing.T) {
+	for _, c := range []struct {
+		cifra    string
+		semitons int
+		bemol    bool
+		want     string
+	}{
+		{"C", 2, false, "D"},
+		{"C", 1, false, "C#"},
+		{"C", 1, true, "Db"},
+		{"Am7/G", 3, false, "Cm7/A#"},
+		{"B", 1, false, "C"},
+		{"G7(9)", -7, true, "C7(9)"},
+	} {
+		got := cifraTeste(t, c.cifra)[0].Transpoe(c.semitons, c.bemol).String()
+		if got != c.want {
+			t.Errorf("%s.Transpoe(%d, %v) = %s, want %s", c.cifra, c.semitons, c.bemol, got, c.want)
+		}
+	}
+}
+
+func TestTranspoeNoTom(t *testing.T) {
+	for _, c := range []struct {
+		cifra, de, para, want string
+	}{
+		{"A7/C#", "A", "Eb", "Eb7/G"},
+		{"A7/C#", "Dm", "Dm", "A7/C#"},
+		{"A7/C#", "Dm", "Ebm", "Bb7/D"},
+		{"E7/G#", "Am", "Gm", "D7/F#"},
+		{"G/B", "C", "Db", "Ab/C"},
+		{"Am", "C", "Eb", "Cm"},
+		{"F", "C", "F#", "B"},
+		{"Bb", "C", "D", "C"},
+		{"C#°", "D", "Eb", "D°"},
+		{"F#m", "D", "Gb", "Bbm"},
+		{"D#m", "F#", "Gb", "Ebm"},
+		{"Db", "C", "C#", "D"},
+		// C# em Bb seria F## em E, grafado pela armadura.
+		{"C#", "Bb", "E", "G"},
+	} {
+		got := cifraTeste(t, c.cifra)[0].TranspoeNoTom(tomTeste(t, c.de), tomTeste(t, c.para)).String()
+		if got != c.want {
+			t.Errorf("%s de %s para %s = %s, want %s", c.cifra, c.de, c.para, got, c.want)
+		}
+	}
+}
+
+func TestIntervalo(t *testing.T) {
+	for _, c := range []struct{ de, para, want int }{
+		{0, 2, 2}, {0, 7, -5}, {0, 6, 6}, {11, 0, 1}, {2, 0, -2},
+	} {
+		if got := Intervalo(c.de, c.para); got != c.want {
+			t.Errorf("Intervalo(%d, %d) = %d, want %d", c.de, c.para, got, c.want)
+		}
+	}
+}
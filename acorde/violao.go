@@ -0,0 +1,114 @@
+package acorde
+
+import "sort"
+
+// Facilidade das formas abertas no violão, de 0 (difícil) a 1 (fácil).
+// Formas que não estão na tabela são consideradas pestanas.
+var formasAbertas = map[string]float64{
+	"C": 1, "D": 1, "E": 1, "G": 1, "A": 1,
+	"Am": 1, "Dm": 1, "Em": 1,
+	"A7": 0.9, "B7": 0.9, "C7": 0.9, "D7": 0.9, "E7": 0.9, "G7": 0.9,
+	"Am7": 0.9, "Dm7": 0.9, "Em7": 0.9,
+	"Asus2": 0.9, "Dsus2": 0.9, "Asus4": 0.9, "Dsus4": 0.9, "Esus4": 0.9,
+	"C7M": 0.8, "D7M": 0.8, "A7M": 0.8, "F7M": 0.8,
+	"E7M": 0.6, "G7M": 0.6, "A6": 0.8, "E6": 0.8, "D6": 0.8,
+	"F": 0.4, "Bm": 0.4, "B°": 0.5,
+}
+
+const (
+	facilidadePestana   = 0.2
+	facilidadeQuinta    = 0.7 // power chords são fáceis em qualquer posição.
+	fatorExtensoes      = 0.8
+	fatorBaixo          = 0.9
+	facilidadeConhecido = 1
+)
+
+// Forma é o desenho de acorde tocado no violão com capotraste.
+type Forma struct {
+	Soando      string  `json:"acorde_soando"`
+	Forma       string  `json:"forma"`
+	Ocorrencias int     `json:"ocorrencias"`
+	Facilidade  float64 `json:"facilidade"`
+}
+
+// PosicaoCapotraste descreve como tocar uma cifra com o capotraste na casa
+// passada.
+type PosicaoCapotraste struct {
+	Casa      int     `json:"casa"`
+	Pontuacao float64 `json:"pontuacao"` // média da facilidade das formas, ponderada pelas ocorrências.
+	Formas    []Forma `json:"formas"`
+}
+
+// FacilidadeNoViolao retorna a facilidade (de 0 a 1) de tocar o acorde no
+// violão sem capotraste.
+func FacilidadeNoViolao(a Acorde) float64 {
+	if f, ok := formasAbertas[a.String()]; ok {
+		return f
+	}
+	if a.Qualidade == QUINTA {
+		return facilidadeQuinta
+	}
+	fator := 1.0
+	if a.Baixo != "" {
+		fator *= fatorBaixo
+		a.Baixo = ""
+	}
+	if len(a.Extensoes) > 0 {
+		fator *= fatorExtensoes
+		a.Extensoes = nil
+	}
+	if f, ok := formasAbertas[a.String()]; ok {
+		return f * fator
+	}
+	return facilidadePestana * fator
+}
+
+// Capotraste calcula, para cada casa de 0 a 11, as formas usadas para tocar
+// a cifra com o capotraste naquela casa e a facilidade de cada uma. Formas
+// cujo acorde (forma enarmônica canônica) está em conhecidos são consideradas
+// fáceis. Retorna as posições ordenadas da mais fácil para a mais difícil.
+func Capotraste(cifra []Acorde, conhecidos map[string]bool) []PosicaoCapotraste {
+	// Ocorrências de cada acorde, na ordem em que aparecem pela primeira vez.
+	var acordes []Acorde
+	ocorrencias := make(map[string]int)
+	for _, a := range cifra {
+		if ocorrencias[a.String()] == 0 {
+			acordes = append(acordes, a)
+		}
+		ocorrencias[a.String()]++
+	}
+
+	var posicoes []PosicaoCapotraste
+	for casa := 0; casa < 12; casa++ {
+		posicao := PosicaoCapotraste{Casa: casa}
+		soma := 0.0
+		for _, a := range acordes {
+			// As formas de acordes abertos são grafadas com sustenidos,
+			// exceto Bb e Eb, mais usuais.
+			forma := a.Transpoe(-casa, false)
+			if forma.Altura() == 10 || forma.Altura() == 3 {
+				forma = forma.Grafa(true)
+			}
+			facilidade := FacilidadeNoViolao(forma)
+			if conhecidos[forma.Enarmonico().String()] {
+				facilidade = facilidadeConhecido
+			}
+			n := ocorrencias[a.String()]
+			soma += facilidade * float64(n)
+			posicao.Formas = append(posicao.Formas, Forma{
+				Soando:      a.String(),
+				Forma:       forma.String(),
+				Ocorrencias: n,
+				Facilidade:  facilidade,
+			})
+		}
+		if len(cifra) > 0 {
+			posicao.Pontuacao = soma / float64(len(cifra))
+		}
+		posicoes = append(posicoes, posicao)
+	}
+	sort.SliceStable(posicoes, func(i, j int) bool {
+		return posicoes[i].Pontuacao > posicoes[j].Pontuacao
+	})
+	return posicoes
+}
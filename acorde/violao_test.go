@@ -0,0 +1,66 @@
+package acorde
+
+import "testing"
+
+func cifraTeste(t *testing.T, acordes ...string) []Acorde {
+	t.Helper()
+	var cifra []Acorde
+	for _, s := range acordes {
+		a, err := Parse(s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		cifra = append(cifra, a)
+	}
+	return cifra
+}
+
+func TestFacilidadeNoViolao(t *testing.T) {
+	for _, c := range []struct {
+		cifra string
+		want  float64
+	}{
+		{"G", 1},
+		{"F", 0.4},
+		{"C#", facilidadePestana},
+		{"E5", facilidadeQuinta},
+		{"Am(9)", fatorExtensoes},
+		{"C/G", fatorBaixo},
+	} {
+		if got := FacilidadeNoViolao(cifraTeste(t, c.cifra)[0]); got != c.want {
+			t.Errorf("FacilidadeNoViolao(%s) = %v, want %v", c.cifra, got, c.want)
+		}
+	}
+}
+
+func TestCapotraste(t *testing.T) {
+	// Em A, com capotraste na casa 2, as formas são as de G.
+	posicoes := Capotraste(cifraTeste(t, "A", "E", "F#m", "D", "A"), nil)
+	if len(posicoes) != 12 {
+		t.Fatalf("len(posicoes) = %d, want 12", len(posicoes))
+	}
+	melhor := posicoes[0]
+	if melhor.Pontuacao != 1 {
+		t.Errorf("melhor pontuação = %v, want 1", melhor.Pontuacao)
+	}
+	formas := map[int]string{}
+	for _, p := range posicoes {
+		if p.Pontuacao == 1 {
+			formas[p.Casa] = p.Formas[2].Forma
+		}
+	}
+	if formas[2] != "Em" || formas[0] != "" {
+		t.Errorf("posições com pontuação máxima: %v", formas)
+	}
+	for _, f := range melhor.Formas {
+		if f.Soando == "A" && f.Ocorrencias != 2 {
+			t.Errorf("ocorrências de A = %d, want 2", f.Ocorrencias)
+		}
+	}
+
+	// Acordes conhecidos são considerados fáceis.
+	posicoes = Capotraste(cifraTeste(t, "F#", "C#"), map[string]bool{"F#": true, "C#": true})
+	if posicoes[0].Casa != 0 || posicoes[0].Pontuacao != facilidadeConhecido {
+		t.Errorf("melhor posição = %+v, want casa 0", posicoes[0])
+	}
+}
@@ -4,16 +4,141 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"sort"
+	"strings"
 
+	"github.com/danielfireman/ciframe-api/acorde"
+	"github.com/danielfireman/ciframe-api/catalog"
 	"github.com/julienschmidt/httprouter"
 )
 
-func AcordesHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	b, err := json.Marshal(acordes)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
+type AcordeResponse struct {
+	Acorde    string        `json:"acorde"`
+	Estrutura acorde.Acorde `json:"estrutura"`
+}
+
+type EstatisticaAcordeResponse struct {
+	*catalog.EstatisticaAcorde
+	Estrutura *acorde.Acorde `json:"estrutura,omitempty"`
+}
+
+type AcordeDetalheResponse struct {
+	*catalog.EstatisticaAcorde
+	Estrutura acorde.Acorde `json:"estrutura"`
+	// Músicas que usam o acorde, ordenadas por popularidade.
+	MusicasPopulares []MusicaResponse `json:"musicas_populares"`
+}
+
+// Ordenações disponíveis em /acordes, selecionadas pelo parâmetro ordem.
+var ordensAcordes = map[string]func(a, b *catalog.EstatisticaAcorde) bool{
+	"musicas": func(a, b *catalog.EstatisticaAcorde) bool {
+		return a.Musicas > b.Musicas
+	},
+	"popularidade": func(a, b *catalog.EstatisticaAcorde) bool {
+		return a.FrequenciaPonderada > b.FrequenciaPonderada
+	},
+	"acorde": func(a, b *catalog.EstatisticaAcorde) bool {
+		return a.Acorde < b.Acorde
+	},
+}
+
+// Retorna os acordes presentes nas músicas, com o número de músicas que usam
+// cada acorde, a frequência ponderada pela popularidade das músicas e os
+// gêneros em que mais aparece. Grafias enarmônicas (A# e Bb) são contadas
+// juntas.
+// params: generos (opcional), ordem (opcional: musicas, o default,
+// popularidade ou acorde) e estrutura (opcional). Caso estrutura=true, cada
+// acorde é retornado junto com sua estrutura (fundamental, qualidade,
+// sétima, extensões e baixo).
+// exemplo 1: /acordes
+// exemplo 2: /acordes?generos=Rock,MPB&ordem=popularidade&estrutura=true
+func AcordesHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		c := ref.Get()
+		queryValues := r.URL.Query()
+		ordem := queryValues.Get("ordem")
+		if ordem == "" {
+			ordem = "musicas"
+		}
+		antes, ok := ordensAcordes[ordem]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		// As estatísticas já são ordenadas pelo acorde em caso de empate.
+		estatisticas := c.EstatisticasAcordes(generosFromRequest(r, c))
+		sort.SliceStable(estatisticas, func(i, j int) bool {
+			return antes(estatisticas[i], estatisticas[j])
+		})
+
+		resposta := []EstatisticaAcordeResponse{}
+		for _, e := range estatisticas {
+			er := EstatisticaAcordeResponse{EstatisticaAcorde: e}
+			if queryValues.Get("estrutura") == "true" {
+				er.Estrutura = &e.Estrutura
+			}
+			resposta = append(resposta, er)
+		}
+		b, err := json.Marshal(resposta)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
+
+// Retorna as estatísticas de uso de um acorde, em qualquer grafia, e as
+// músicas mais populares que o usam. O serviço é paginado. Acordes com baixo
+// (G/B) podem ser passados diretamente no caminho; acordes com sustenido
+// devem ter o # codificado (%23).
+// params: generos (opcional) e pagina (opcional).
+// exemplo 1: /acordes/Am
+// exemplo 2: /acordes/G/B?generos=Rock
+func GetAcordeHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		c := ref.Get()
+		a := strings.TrimPrefix(p.ByName("acorde"), "/")
+		pagina, err := getPaginaFromRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		estrutura, err := acorde.Parse(a)
+		if err != nil {
+			escreveErro(w, http.StatusBadRequest, fmt.Sprintf("acorde inválido: %q", a))
+			return
+		}
+		generos := generosFromRequest(r, c)
+		estatistica, ok := c.EstatisticaAcorde(a, generos)
+		if !ok {
+			escreveErro(w, http.StatusNotFound, fmt.Sprintf("acorde não encontrado: %q", a))
+			return
+		}
+
+		resposta := AcordeDetalheResponse{
+			EstatisticaAcorde: estatistica,
+			Estrutura:         estrutura,
+			MusicasPopulares:  []MusicaResponse{},
+		}
+		musicas := c.MusicasComAcorde(a, generos)
+		i, f := catalog.LimitesDaPagina(len(musicas), pagina)
+		for _, m := range musicas[i:f] {
+			resposta.MusicasPopulares = append(resposta.MusicasPopulares, MusicaResponse{
+				UniqueID:     m.UniqueID,
+				Artista:      m.Artista,
+				Nome:         m.Nome,
+				Popularidade: m.Popularidade,
+				URL:          m.URL,
+			})
+		}
+		b, err := json.Marshal(resposta)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
 	}
-	fmt.Fprintf(w, string(b))
-	w.WriteHeader(http.StatusOK)
 }
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Retorna os artistas ordenados pela soma das popularidades das suas músicas,
+// com estatísticas sobre as músicas (número de músicas, popularidade total,
+// acordes mais usados e tom típico). O serviço é paginado.
+// params: generos (opcional) e pagina (opcional).
+// exemplo 1: /artistas
+// exemplo 2: /artistas?generos=Rock,MPB&pagina=2
+func ArtistasHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		c := ref.Get()
+		pagina, err := getPaginaFromRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		artistas := c.Artistas(generosFromRequest(r, c))
+		i, f := catalog.LimitesDaPagina(len(artistas), pagina)
+		resposta := artistas[i:f]
+		if resposta == nil {
+			resposta = []*catalog.Artista{}
+		}
+		b, err := json.Marshal(resposta)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
+
+// Retorna um artista e as estatísticas sobre suas músicas.
+// exemplo: /artistas/legiao-urbana
+func GetArtistaHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		a, ok := ref.Get().Artista(p.ByName("id"))
+		if !ok {
+			escreveErro(w, http.StatusNotFound, fmt.Sprintf("artista não encontrado: %q", p.ByName("id")))
+			return
+		}
+		b, err := json.Marshal(a)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
+
+// Retorna as músicas de um artista, ordenadas por popularidade. O serviço é
+// paginado.
+// params: pagina (opcional).
+// exemplo: /artistas/legiao-urbana/musicas
+func ArtistaMusicasHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		a, ok := ref.Get().Artista(p.ByName("id"))
+		if !ok {
+			escreveErro(w, http.StatusNotFound, fmt.Sprintf("artista não encontrado: %q", p.ByName("id")))
+			return
+		}
+		pagina, err := getPaginaFromRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		i, f := catalog.LimitesDaPagina(len(a.Musicas()), pagina)
+		b, err := json.Marshal(a.Musicas()[i:f])
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
@@ -0,0 +1,48 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Sugere artistas e músicas cujo nome possui uma palavra iniciada por q, sem
+// distinção de maiúsculas e acentos, ordenados por popularidade. Cada
+// sugestão informa o tipo (artista ou musica) e o id usado em /artistas/:id
+// ou /musica/:id.
+// params: q, generos (opcional) e n (opcional, número de sugestões, até 50).
+// exemplo 1: /autocomplete?q=legi
+// exemplo 2: /autocomplete?q=tempo&generos=Rock&n=5
+func AutocompleteHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		c := ref.Get()
+		queryValues := r.URL.Query()
+		if queryValues.Get("q") == "" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		n := catalog.NUM_SUGESTOES
+		if queryValues.Get("n") != "" {
+			var err error
+			n, err = strconv.Atoi(queryValues.Get("n"))
+			if err != nil || n < 1 || n > catalog.MAX_SUGESTOES {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		sugestoes := c.Sugestoes(queryValues.Get("q"), generosFromRequest(r, c), n)
+		if sugestoes == nil {
+			sugestoes = []*catalog.Sugestao{}
+		}
+		b, err := json.Marshal(sugestoes)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	"github.com/danielfireman/ciframe-api/catalog"
+	"github.com/julienschmidt/httprouter"
+)
+
+type CapotrasteResponse struct {
+	UniqueID string                     `json:"id_unico_musica,omitempty"`
+	Posicoes []acorde.PosicaoCapotraste `json:"posicoes"`
+}
+
+// Sugere posições do capotraste (casas 0 a 11) para tocar uma música no
+// violão com formas abertas. As posições são ordenadas da mais fácil para a
+// mais difícil.
+// params: id_unico_musica ou acordes (na ordem da cifra) e conhecidos
+// (opcional). Os acordes conhecidos são considerados fáceis de tocar.
+// exemplo 1: /capotraste?id_unico_musica=legiao-urbana_tempo-perdido
+// exemplo 2: /capotraste?acordes=F,Bb,C,Dm&conhecidos=C,G,D,Em,Am
+func CapotrasteHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		c := ref.Get()
+		queryValues := r.URL.Query()
+		var resposta CapotrasteResponse
+		var estruturas []acorde.Acorde
+		switch {
+		case queryValues.Get("id_unico_musica") != "":
+			m, ok := c.Musica(queryValues.Get("id_unico_musica"))
+			if !ok {
+				escreveErro(w, http.StatusNotFound, fmt.Sprintf("música não encontrada: %q", queryValues.Get("id_unico_musica")))
+				return
+			}
+			resposta.UniqueID = m.UniqueID
+			estruturas = m.Estruturas
+		case queryValues.Get("acordes") != "":
+			for _, a := range strings.Split(queryValues.Get("acordes"), ",") {
+				estrutura, err := acorde.Parse(a)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				estruturas = append(estruturas, estrutura)
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		conhecidos := make(map[string]bool)
+		if queryValues.Get("conhecidos") != "" {
+			for _, a := range strings.Split(queryValues.Get("conhecidos"), ",") {
+				if chave, err := acorde.ChaveEnarmonica(a); err == nil {
+					conhecidos[chave] = true
+				}
+			}
+		}
+		resposta.Posicoes = acorde.Capotraste(estruturas, conhecidos)
+
+		b, err := json.Marshal(resposta)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
@@ -0,0 +1,206 @@
+package catalog
+
+import (
+	"sort"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+// Número de músicas de exemplo retornadas para cada acorde candidato.
+const NUM_EXEMPLOS = 3
+
+// Candidato é um acorde que, se aprendido, permite tocar novas músicas.
+type Candidato struct {
+	Acorde    string   `json:"acorde"`
+	Musicas   int      `json:"musicas"`   // número de músicas desbloqueadas.
+	Pontuacao float64  `json:"pontuacao"` // músicas desbloqueadas ou soma das suas popularidades.
+	Exemplos  []string `json:"exemplos"`  // ids das músicas desbloqueadas mais populares.
+}
+
+// ProximosAcordes ordena os acordes que ainda não são conhecidos pelo número
+// de músicas que passam a ser tocáveis quando combinados aos acordes
+// conhecidos (na forma enarmônica canônica). Apenas músicas dos gêneros
+// passados são consideradas (todas, caso nenhum gênero seja passado). Caso
+// ponderado seja verdadeiro, cada música conta de acordo com sua popularidade.
+func (c *Catalog) ProximosAcordes(conhecidos sets.Set, generos sets.Set, ponderado bool) []*Candidato {
+	porAcorde := make(map[string]*Candidato)
+	// Número de ocorrências de cada grafia dos acordes candidatos.
+	grafias := make(map[string]map[string]int)
+	desbloqueadas := make(map[string][]*Musica)
+	for id := range c.Tocaveis(conhecidos, 1).Iter() {
+		m := c.musicasDict[id.(string)]
+		if generos.Cardinality() > 0 && !generos.Contains(m.Genero) {
+			continue
+		}
+		// Músicas que precisam de exatamente um acorde novo.
+		var novo, grafia string
+		n := 0
+		for a := range m.Acordes().Iter() {
+			if chave := m.ChaveEnarmonica(a.(string)); !conhecidos.Contains(chave) && chave != novo {
+				novo, grafia = chave, a.(string)
+				n++
+			}
+		}
+		if n != 1 {
+			continue
+		}
+		candidato, ok := porAcorde[novo]
+		if !ok {
+			candidato = &Candidato{Acorde: novo}
+			porAcorde[novo] = candidato
+			grafias[novo] = make(map[string]int)
+		}
+		candidato.Musicas++
+		if ponderado {
+			candidato.Pontuacao += float64(m.Popularidade)
+		} else {
+			candidato.Pontuacao++
+		}
+		grafias[novo][grafia]++
+		desbloqueadas[novo] = append(desbloqueadas[novo], m)
+	}
+
+	candidatos := []*Candidato{}
+	for chave, candidato := range porAcorde {
+		// O acorde é apresentado com a grafia mais usada nas músicas.
+		for g, n := range grafias[chave] {
+			if n > grafias[chave][candidato.Acorde] || (n == grafias[chave][candidato.Acorde] && g < candidato.Acorde) {
+				candidato.Acorde = g
+			}
+		}
+		musicas := desbloqueadas[chave]
+		sort.Sort(PorPopularidade(musicas))
+		for i := 0; i < len(musicas) && i < NUM_EXEMPLOS; i++ {
+			candidato.Exemplos = append(candidato.Exemplos, musicas[i].UniqueID)
+		}
+		candidatos = append(candidatos, candidato)
+	}
+	sort.Slice(candidatos, func(i, j int) bool {
+		if candidatos[i].Pontuacao != candidatos[j].Pontuacao {
+			return candidatos[i].Pontuacao > candidatos[j].Pontuacao
+		}
+		return candidatos[i].Acorde < candidatos[j].Acorde
+	})
+	return candidatos
+}
+
+// Número de músicas para praticar sugeridas em cada etapa da trilha.
+const NUM_PRATICA = 3
+
+// Número de acordes, entre os mais frequentes no catálogo, combinados em
+// pares quando nenhum acorde isolado desbloqueia músicas.
+const MAX_CANDIDATOS_PAR = 8
+
+// Etapa é um passo da trilha de aprendizado: os acordes a aprender e
+// músicas populares para praticá-los, que usam apenas os acordes aprendidos
+// até a etapa.
+type Etapa struct {
+	Acordes []string
+	Musicas []*Musica
+}
+
+// Trilha retorna a sequência de etapas para, partindo dos acordes
+// conhecidos (na forma enarmônica canônica), aprender todos os acordes da
+// música alvo. Cada etapa adiciona um acorde ou, quando nenhum acorde
+// isolado permite tocar novas músicas, dois acordes (escolhidos entre os
+// MAX_CANDIDATOS_PAR mais frequentes no catálogo). Os acordes que
+// desbloqueiam as músicas mais populares são aprendidos primeiro.
+func (c *Catalog) Trilha(alvo *Musica, conhecidos sets.Set) []*Etapa {
+	d := c.dicionarios[COMPARA_ENARMONICO]
+	aprendidos := novoBitset(len(d.idf))
+	aprendidos.une(c.bits(COMPARA_ENARMONICO, conhecidos))
+	var faltam []string
+	for _, a := range alvo.AcordesEnarmonicos().ToSlice() {
+		if !conhecidos.Contains(a) {
+			faltam = append(faltam, a.(string))
+		}
+	}
+	// Os pares são formados a partir dos acordes mais frequentes.
+	sort.Slice(faltam, func(i, j int) bool {
+		fi, fj := len(d.musicas[d.numeros[faltam[i]]]), len(d.musicas[d.numeros[faltam[j]]])
+		if fi != fj {
+			return fi > fj
+		}
+		return faltam[i] < faltam[j]
+	})
+	etapas := []*Etapa{}
+	for len(faltam) > 0 {
+		var candidatos [][]string
+		for _, a := range faltam {
+			candidatos = append(candidatos, []string{a})
+		}
+		novos, musicas := c.melhorEtapa(alvo, aprendidos, candidatos)
+		if len(musicas) == 0 && len(faltam) > 1 {
+			var pares [][]string
+			for i := 0; i < len(faltam) && i < MAX_CANDIDATOS_PAR; i++ {
+				for j := i + 1; j < len(faltam) && j < MAX_CANDIDATOS_PAR; j++ {
+					pares = append(pares, []string{faltam[i], faltam[j]})
+				}
+			}
+			if par, musicasPar := c.melhorEtapa(alvo, aprendidos, pares); len(musicasPar) > 0 {
+				novos, musicas = par, musicasPar
+			}
+		}
+
+		etapa := &Etapa{}
+		for _, a := range novos {
+			aprendidos.adiciona(d.numeros[a])
+			etapa.Acordes = append(etapa.Acordes, alvo.grafia(a))
+		}
+		restantes := faltam[:0]
+		for _, a := range faltam {
+			if !aprendidos.contem(d.numeros[a]) {
+				restantes = append(restantes, a)
+			}
+		}
+		faltam = restantes
+		sort.Sort(PorPopularidade(musicas))
+		if len(musicas) > NUM_PRATICA {
+			musicas = musicas[:NUM_PRATICA]
+		}
+		etapa.Musicas = musicas
+		etapas = append(etapas, etapa)
+	}
+	return etapas
+}
+
+// melhorEtapa escolhe, entre os conjuntos de acordes candidatos, aquele
+// que somado aos acordes aprendidos (no dicionário enarmônico) desbloqueia
+// as músicas com maior popularidade total. Em caso de empate, prefere os
+// acordes mais frequentes no catálogo. Retorna os acordes escolhidos e as
+// músicas desbloqueadas.
+func (c *Catalog) melhorEtapa(alvo *Musica, aprendidos bitset, candidatos [][]string) ([]string, []*Musica) {
+	d := c.dicionarios[COMPARA_ENARMONICO]
+	var melhor []string
+	var melhoresMusicas []*Musica
+	melhorPontuacao, melhorFrequencia := -1, -1
+	conhecidos := novoBitset(len(d.idf))
+	for _, novos := range candidatos {
+		var novosBits bitset
+		frequencia := 0
+		for _, a := range novos {
+			n := d.numeros[a]
+			novosBits.adiciona(n)
+			frequencia += len(d.musicas[n])
+		}
+		copy(conhecidos, aprendidos)
+		conhecidos.une(novosBits)
+		var musicas []*Musica
+		pontuacao := 0
+		c.tocaveis(conhecidos, 0, func(p int) {
+			m := c.musicas[p]
+			if m == alvo || m.termos[COMPARA_ENARMONICO].intersecao(novosBits) == 0 {
+				return
+			}
+			musicas = append(musicas, m)
+			pontuacao += m.Popularidade
+		})
+		if pontuacao > melhorPontuacao ||
+			(pontuacao == melhorPontuacao && frequencia > melhorFrequencia) ||
+			(pontuacao == melhorPontuacao && frequencia == melhorFrequencia && novos[0] < melhor[0]) {
+			melhor, melhoresMusicas = novos, musicas
+			melhorPontuacao, melhorFrequencia = pontuacao, frequencia
+		}
+	}
+	return melhor, melhoresMusicas
+}
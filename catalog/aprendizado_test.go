@@ -0,0 +1,156 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+func TestProximosAcordes(t *testing.T) {
+	c := catalogTeste(t,
+		"x,a,X,A,Rock,500,C,NA,C;G;Am;F",
+		"x,b,X,B,MPB,400,C,NA,C;G;Am;F;Dm",
+		"x,c,X,C,Rock,300,G,NA,C;G;D",
+		"x,d,X,D,Rock,200,G,NA,C;G;D;Em",
+		"y,e,Y,E,Rock,100,F,NA,C;G;Bb",
+		"y,f,Y,F,Rock,50,F,NA,C;G;A#",
+		"y,g,Y,G,Rock,20,F,NA,F;Bb;C",
+	)
+	conhecidos := sets.NewSet("C", "G", "Am", "F")
+	type candidato struct {
+		acorde    string
+		musicas   int
+		pontuacao float64
+		exemplos  []string
+	}
+	for _, q := range []struct {
+		generos   []string
+		ponderado bool
+		want      []candidato
+	}{
+		// Bb e A# são o mesmo acorde, apresentado com a grafia mais usada. D
+		// não conta as músicas que também precisam de Em.
+		{nil, false, []candidato{
+			{"Bb", 3, 3, []string{"y_e", "y_f", "y_g"}},
+			{"D", 1, 1, []string{"x_c"}},
+			{"Dm", 1, 1, []string{"x_b"}},
+		}},
+		{nil, true, []candidato{
+			{"Dm", 1, 400, []string{"x_b"}},
+			{"D", 1, 300, []string{"x_c"}},
+			{"Bb", 3, 170, []string{"y_e", "y_f", "y_g"}},
+		}},
+		{[]string{"MPB"}, false, []candidato{
+			{"Dm", 1, 1, []string{"x_b"}},
+		}},
+	} {
+		generos := sets.NewSet()
+		for _, g := range q.generos {
+			generos.Add(g)
+		}
+		var got []candidato
+		for _, cand := range c.ProximosAcordes(conhecidos, generos, q.ponderado) {
+			got = append(got, candidato{cand.Acorde, cand.Musicas, cand.Pontuacao, cand.Exemplos})
+		}
+		if !reflect.DeepEqual(got, q.want) {
+			t.Errorf("ProximosAcordes(%v, %v, %v) = %v, want %v", conhecidos, q.generos, q.ponderado, got, q.want)
+		}
+	}
+}
+
+// verificaTrilha verifica que cada etapa da trilha adiciona um ou dois
+// acordes novos da música alvo, que as músicas para praticar usam apenas os
+// acordes aprendidos até a etapa e que, ao final, todos os acordes da música
+// alvo foram aprendidos.
+func verificaTrilha(t *testing.T, alvo *Musica, conhecidos sets.Set, etapas []*Etapa) {
+	t.Helper()
+	aprendidos := conhecidos.Clone()
+	for i, e := range etapas {
+		if len(e.Acordes) < 1 || len(e.Acordes) > 2 {
+			t.Errorf("%s, etapa %d: %d acordes, want 1 ou 2", alvo.UniqueID, i, len(e.Acordes))
+		}
+		novos := sets.NewSet()
+		for _, a := range e.Acordes {
+			chave := alvo.ChaveEnarmonica(a)
+			if aprendidos.Contains(chave) || !alvo.AcordesEnarmonicos().Contains(chave) {
+				t.Errorf("%s, etapa %d: acorde %s não é novo na música alvo", alvo.UniqueID, i, a)
+			}
+			novos.Add(chave)
+		}
+		aprendidos = aprendidos.Union(novos)
+		if len(e.Musicas) > NUM_PRATICA {
+			t.Errorf("%s, etapa %d: %d músicas para praticar", alvo.UniqueID, i, len(e.Musicas))
+		}
+		for _, m := range e.Musicas {
+			if m == alvo || !m.AcordesEnarmonicos().IsSubset(aprendidos) || m.AcordesEnarmonicos().Intersect(novos).Cardinality() == 0 {
+				t.Errorf("%s, etapa %d: música %s não pratica os acordes da etapa", alvo.UniqueID, i, m.UniqueID)
+			}
+		}
+	}
+	if !alvo.AcordesEnarmonicos().IsSubset(aprendidos) {
+		t.Errorf("%s: trilha não aprende %v", alvo.UniqueID, alvo.AcordesEnarmonicos().Difference(aprendidos))
+	}
+}
+
+func TestTrilha(t *testing.T) {
+	c := catalogTeste(t,
+		"t,alvo,T,Alvo,Rock,1000,C,NA,C;G;Am;F;Dm;E7",
+		"t,a,T,A,Rock,500,C,NA,C;G",
+		"t,b,T,B,Rock,400,C,NA,C;G;Am",
+		"t,c,T,C,Rock,300,C,NA,C;F",
+		"t,d,T,D,Rock,200,C,NA,C;G;Am;F;Dm",
+		"t,e,T,E,Rock,100,Am,NA,Am;E7;F",
+		"u,alvo,U,Alvo,MPB,90,D,NA,C;Bm;F#",
+		"u,a,U,A,MPB,50,D,NA,Bm;F#;C",
+	)
+	for _, q := range []struct {
+		alvo       string
+		conhecidos []string
+		// Acordes e músicas para praticar de cada etapa.
+		want [][2][]string
+	}{
+		{"t_alvo", []string{"C"}, [][2][]string{
+			{{"G"}, {"t_a"}},
+			{{"Am"}, {"t_b"}},
+			{{"F"}, {"t_c"}},
+			{{"Dm"}, {"t_d"}},
+			{{"E7"}, {"t_e"}},
+		}},
+		// Nenhum acorde isolado desbloqueia músicas: Bm e F# são aprendidos
+		// juntos.
+		{"u_alvo", []string{"C"}, [][2][]string{
+			{{"Bm", "F#"}, {"u_a"}},
+		}},
+		{"u_alvo", []string{"C", "Bm", "F#"}, nil},
+	} {
+		alvo, _ := c.Musica(q.alvo)
+		conhecidos := sets.NewSet()
+		for _, a := range q.conhecidos {
+			conhecidos.Add(a)
+		}
+		etapas := c.Trilha(alvo, conhecidos)
+		verificaTrilha(t, alvo, conhecidos, etapas)
+		var got [][2][]string
+		for _, e := range etapas {
+			var musicas []string
+			for _, m := range e.Musicas {
+				musicas = append(musicas, m.UniqueID)
+			}
+			got = append(got, [2][]string{e.Acordes, musicas})
+		}
+		if !reflect.DeepEqual(got, q.want) {
+			t.Errorf("Trilha(%s, %v) = %v, want %v", q.alvo, q.conhecidos, got, q.want)
+		}
+	}
+}
+
+// As propriedades da trilha também valem em um catálogo maior, no qual as
+// músicas alvo possuem acordes raros.
+func TestTrilhaCatalogSintetico(t *testing.T) {
+	c := catalogSintetico(2000, palavrasSinteticas)
+	conhecidos := sets.NewSet("G", "C", "D")
+	for _, m := range c.musicas[:50] {
+		verificaTrilha(t, m, conhecidos, c.Trilha(m, conhecidos))
+	}
+}
@@ -0,0 +1,158 @@
+package catalog
+
+import (
+	"sort"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+// Número de acordes mais usados nas estatísticas de cada artista.
+const NUM_ACORDES_ARTISTA = 10
+
+// Frequencia é o número de músicas em que um valor (acorde, tom ou gênero) aparece.
+type Frequencia struct {
+	Valor   string `json:"valor"`
+	Musicas int    `json:"musicas"`
+}
+
+// contador conta em quantas músicas cada valor aparece. Os valores são
+// identificados por uma chave (a forma enarmônica, no caso dos acordes) e
+// exibidos com a primeira grafia encontrada. Empates são desfeitos pela
+// ordem em que as chaves apareceram.
+type contador struct {
+	frequencias map[string]*Frequencia
+	ordem       []*Frequencia
+}
+
+func novoContador() *contador {
+	return &contador{frequencias: make(map[string]*Frequencia)}
+}
+
+func (c *contador) conta(chave, valor string) {
+	f, ok := c.frequencias[chave]
+	if !ok {
+		f = &Frequencia{Valor: valor}
+		c.frequencias[chave] = f
+		c.ordem = append(c.ordem, f)
+	}
+	f.Musicas++
+}
+
+// maisFrequentes retorna os n valores que aparecem em mais músicas.
+func (c *contador) maisFrequentes(n int) []Frequencia {
+	ordem := append([]*Frequencia{}, c.ordem...)
+	sort.SliceStable(ordem, func(i, j int) bool {
+		return ordem[i].Musicas > ordem[j].Musicas
+	})
+	if len(ordem) > n {
+		ordem = ordem[:n]
+	}
+	frequencias := []Frequencia{}
+	for _, f := range ordem {
+		frequencias = append(frequencias, *f)
+	}
+	return frequencias
+}
+
+// contaAcordes conta os acordes da música, identificados pela forma
+// enarmônica.
+func (c *contador) contaAcordes(m *Musica) {
+	vistos := make(map[string]bool)
+	for _, a := range m.Cifra {
+		chave := m.ChaveEnarmonica(a)
+		if !vistos[chave] {
+			vistos[chave] = true
+			c.conta(chave, a)
+		}
+	}
+}
+
+// Artista reúne as músicas de um artista e estatísticas sobre elas.
+type Artista struct {
+	ID      string   `json:"id_artista"`
+	Nome    string   `json:"nome_artista"`
+	Generos []string `json:"generos"`
+	// Número de músicas e soma das suas popularidades.
+	NumMusicas   int `json:"musicas"`
+	Popularidade int `json:"popularidade"`
+	// Acordes mais usados e tom mais frequente entre as músicas.
+	Acordes []Frequencia `json:"acordes_mais_usados"`
+	Tom     string       `json:"tom_tipico"`
+
+	musicas []*Musica // ordenadas por popularidade.
+	generos sets.Set
+}
+
+// Musicas retorna as músicas do artista, ordenadas por popularidade.
+func (a *Artista) Musicas() []*Musica {
+	return a.musicas
+}
+
+// indexaArtistas agrupa as músicas por artista e calcula as estatísticas de
+// cada um. As músicas já devem estar ordenadas.
+func (c *Catalog) indexaArtistas() {
+	c.artistas = nil
+	c.artistasDict = make(map[string]*Artista)
+	acordes := make(map[string]*contador)
+	tons := make(map[string]*contador)
+	for _, m := range c.musicas {
+		a, ok := c.artistasDict[m.IDArtista]
+		if !ok {
+			a = &Artista{ID: m.IDArtista, Nome: m.Artista, generos: sets.NewSet()}
+			c.artistasDict[m.IDArtista] = a
+			c.artistas = append(c.artistas, a)
+			acordes[a.ID], tons[a.ID] = novoContador(), novoContador()
+		}
+		a.musicas = append(a.musicas, m)
+		a.NumMusicas++
+		a.Popularidade += m.Popularidade
+		a.generos.Add(m.Genero)
+		acordes[a.ID].contaAcordes(m)
+		if tom, ok := m.Tonalidade(); ok {
+			// A# e Bb são o mesmo tom, grafado de acordo com a armadura.
+			tom = tom.Transpoe(0)
+			tons[a.ID].conta(tom.String(), tom.String())
+		}
+	}
+	for _, a := range c.artistas {
+		for g := range a.generos.Iter() {
+			a.Generos = append(a.Generos, g.(string))
+		}
+		sort.Strings(a.Generos)
+		a.Acordes = acordes[a.ID].maisFrequentes(NUM_ACORDES_ARTISTA)
+		if t := tons[a.ID].maisFrequentes(1); len(t) > 0 {
+			a.Tom = t[0].Valor
+		}
+	}
+	sort.SliceStable(c.artistas, func(i, j int) bool {
+		if c.artistas[i].Popularidade != c.artistas[j].Popularidade {
+			return c.artistas[i].Popularidade > c.artistas[j].Popularidade
+		}
+		return c.artistas[i].ID < c.artistas[j].ID
+	})
+}
+
+// Artista retorna o artista identificado pelo id.
+func (c *Catalog) Artista(id string) (*Artista, bool) {
+	a, ok := c.artistasDict[id]
+	return a, ok
+}
+
+// Artistas retorna os artistas com músicas de algum dos gêneros passados,
+// ordenados pela soma das popularidades das suas músicas. Caso nenhum gênero
+// seja passado, retorna todos os artistas.
+func (c *Catalog) Artistas(generos sets.Set) []*Artista {
+	if generos.Cardinality() == 0 {
+		return c.artistas
+	}
+	var artistas []*Artista
+	for _, a := range c.artistas {
+		for _, g := range generos.ToSlice() {
+			if a.generos.Contains(g) {
+				artistas = append(artistas, a)
+				break
+			}
+		}
+	}
+	return artistas
+}
@@ -0,0 +1,75 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+var musicasArtistas = []string{
+	"l,a,L,A,Rock,300,C,NA,C;G;Am;F",
+	"l,b,L,B,MPB,200,A#,NA,Bb;F;Gm;C",
+	"l,c,L,C,Rock,100,Bb,NA,A#;F;C",
+	"t,d,T,D,Rock,700,G,NA,G;D;Em;C",
+	"z,e,Z,E,Samba,50,D,NA,D;A",
+}
+
+func TestArtista(t *testing.T) {
+	c := catalogTeste(t, musicasArtistas...)
+	a, ok := c.Artista("l")
+	if !ok {
+		t.Fatal("Artista(l) não encontrado")
+	}
+	if a.Nome != "L" || a.NumMusicas != 3 || a.Popularidade != 600 {
+		t.Errorf("Artista(l) = %+v, want 3 músicas com popularidade 600", a)
+	}
+	if want := []string{"MPB", "Rock"}; !reflect.DeepEqual(a.Generos, want) {
+		t.Errorf("Artista(l).Generos = %v, want %v", a.Generos, want)
+	}
+	var musicas []string
+	for _, m := range a.Musicas() {
+		musicas = append(musicas, m.UniqueID)
+	}
+	if want := []string{"l_a", "l_b", "l_c"}; !reflect.DeepEqual(musicas, want) {
+		t.Errorf("Artista(l).Musicas() = %v, want %v", musicas, want)
+	}
+	// Bb e A# são contados juntos, com a grafia da música mais popular.
+	// Empates mantêm a ordem em que os acordes aparecem.
+	wantAcordes := []Frequencia{{"C", 3}, {"F", 3}, {"Bb", 2}, {"G", 1}, {"Am", 1}, {"Gm", 1}}
+	if !reflect.DeepEqual(a.Acordes, wantAcordes) {
+		t.Errorf("Artista(l).Acordes = %v, want %v", a.Acordes, wantAcordes)
+	}
+	// A# e Bb também são o mesmo tom.
+	if a.Tom != "Bb" {
+		t.Errorf("Artista(l).Tom = %q, want Bb", a.Tom)
+	}
+	if _, ok := c.Artista("nada"); ok {
+		t.Error("Artista(nada) encontrado")
+	}
+}
+
+func TestArtistas(t *testing.T) {
+	c := catalogTeste(t, musicasArtistas...)
+	for _, q := range []struct {
+		generos []string
+		want    []string
+	}{
+		{nil, []string{"t", "l", "z"}},
+		{[]string{"Rock"}, []string{"t", "l"}},
+		{[]string{"Samba", "MPB"}, []string{"l", "z"}},
+		{[]string{"Forró"}, nil},
+	} {
+		generos := sets.NewSet()
+		for _, g := range q.generos {
+			generos.Add(g)
+		}
+		var got []string
+		for _, a := range c.Artistas(generos) {
+			got = append(got, a.ID)
+		}
+		if !reflect.DeepEqual(got, q.want) {
+			t.Errorf("Artistas(%v) = %v, want %v", q.generos, got, q.want)
+		}
+	}
+}
@@ -0,0 +1,90 @@
+package catalog
+
+import "math/bits"
+
+// bitset é um conjunto de inteiros não negativos representado por palavras
+// de 64 bits. As operações entre conjuntos são feitas palavra a palavra e
+// aceitam operandos de tamanhos diferentes (palavras ausentes valem zero).
+type bitset []uint64
+
+// novoBitset retorna um conjunto vazio com capacidade para os inteiros em
+// [0, n) sem realocação.
+func novoBitset(n int) bitset {
+	return make(bitset, (n+63)/64)
+}
+
+// adiciona inclui i no conjunto, aumentando-o se necessário.
+func (b *bitset) adiciona(i int) {
+	p := i / 64
+	for len(*b) <= p {
+		*b = append(*b, 0)
+	}
+	(*b)[p] |= 1 << uint(i%64)
+}
+
+// contem retorna verdadeiro se i pertence ao conjunto.
+func (b bitset) contem(i int) bool {
+	p := i / 64
+	return p < len(b) && b[p]&(1<<uint(i%64)) != 0
+}
+
+// cardinalidade retorna o número de elementos do conjunto.
+func (b bitset) cardinalidade() int {
+	n := 0
+	for _, w := range b {
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// intersecao retorna o número de elementos em comum com o.
+func (b bitset) intersecao(o bitset) int {
+	if len(o) < len(b) {
+		b = b[:len(o)]
+	}
+	n := 0
+	for i, w := range b {
+		n += bits.OnesCount64(w & o[i])
+	}
+	return n
+}
+
+// diferenca retorna o número de elementos de b que não pertencem a o.
+func (b bitset) diferenca(o bitset) int {
+	n := 0
+	for i, w := range b {
+		if i < len(o) {
+			w &^= o[i]
+		}
+		n += bits.OnesCount64(w)
+	}
+	return n
+}
+
+// une inclui em b os elementos de o. b deve ter ao menos o tamanho de o.
+func (b bitset) une(o bitset) {
+	for i, w := range o {
+		b[i] |= w
+	}
+}
+
+// intersecta remove de b os elementos que não pertencem a o.
+func (b bitset) intersecta(o bitset) {
+	for i := range b {
+		if i < len(o) {
+			b[i] &= o[i]
+		} else {
+			b[i] = 0
+		}
+	}
+}
+
+// itera chama f para cada elemento do conjunto, em ordem crescente.
+func (b bitset) itera(f func(i int)) {
+	for p, w := range b {
+		for w != 0 {
+			f(p*64 + bits.TrailingZeros64(w))
+			w &= w - 1
+		}
+	}
+}
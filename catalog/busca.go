@@ -0,0 +1,316 @@
+package catalog
+
+import (
+	"math"
+	"sort"
+	"strings"
+	"unicode"
+
+	sets "github.com/deckarep/golang-set"
+	"golang.org/x/text/transform"
+	"golang.org/x/text/unicode/norm"
+)
+
+const (
+	// Peso de um termo encontrado por prefixo quando a consulta cobre todo o
+	// termo. O peso diminui com a fração do termo que falta ser digitada.
+	PESO_PREFIXO = 0.8
+	// Peso da popularidade no score da busca. O restante do score é a
+	// relevância dos termos encontrados.
+	PESO_POPULARIDADE = 0.3
+)
+
+// Formas como um termo da consulta pode ser encontrado no nome do artista ou
+// da música.
+const (
+	TERMO_EXATO      = "exato"
+	TERMO_PREFIXO    = "prefixo"    // apenas o último termo da consulta.
+	TERMO_APROXIMADO = "aproximado" // termo corrigido, a distância de edição limitada.
+)
+
+var diacriticosTransformer = transform.Chain(
+	norm.NFD,
+	transform.RemoveFunc(
+		func(r rune) bool {
+			return unicode.Is(unicode.Mn, r) // Mn: nonspacing marks
+		}),
+	norm.NFC)
+
+// RemoverCombinantes remove os acentos e demais marcas combinantes do texto.
+func RemoverCombinantes(s string) string {
+	r, _, _ := transform.String(diacriticosTransformer, s)
+	return r
+}
+
+// termosBusca separa o texto, sem distinção de maiúsculas e acentos, nos
+// termos comparados pela busca.
+func termosBusca(s string) []string {
+	return strings.Fields(RemoverCombinantes(strings.ToLower(s)))
+}
+
+// maxDistancia retorna a distância de edição admitida na correção de um termo
+// com n letras. Termos curtos não são corrigidos.
+func maxDistancia(n int) int {
+	switch {
+	case n < 4:
+		return 0
+	case n < 7:
+		return 1
+	default:
+		return 2
+	}
+}
+
+// indexaBusca constrói o índice invertido dos termos do nome do artista e do
+// nome de cada música. As listas de posições ficam em ordem crescente, ou
+// seja, por popularidade. Os termos também são guardados em ordem alfabética,
+// para as buscas por prefixo e por aproximação, com a grafia (acentuada) em
+// que aparecem pela primeira vez.
+func (c *Catalog) indexaBusca() {
+	c.indiceBusca = make(map[string][]int32)
+	c.grafiasBusca = make(map[string]string)
+	for p, m := range c.musicas {
+		termos := termosBusca(m.Artista + " " + m.Nome)
+		grafias := strings.Fields(strings.ToLower(m.Artista + " " + m.Nome))
+		if len(grafias) != len(termos) {
+			// Palavras formadas apenas por acentos desaparecem na normalização.
+			grafias = termos
+		}
+		for i, t := range termos {
+			posicoes, ok := c.indiceBusca[t]
+			if !ok {
+				c.grafiasBusca[t] = grafias[i]
+			}
+			// Termos repetidos na mesma música são indexados uma única vez.
+			if len(posicoes) == 0 || posicoes[len(posicoes)-1] != int32(p) {
+				c.indiceBusca[t] = append(posicoes, int32(p))
+			}
+		}
+	}
+	c.vocabularioBusca = nil
+	for t := range c.indiceBusca {
+		c.vocabularioBusca = append(c.vocabularioBusca, t)
+	}
+	sort.Strings(c.vocabularioBusca)
+	c.runasBusca = make([][]rune, len(c.vocabularioBusca))
+	c.tamanhosBusca = nil
+	for i, t := range c.vocabularioBusca {
+		c.runasBusca[i] = []rune(t)
+		n := len(c.runasBusca[i])
+		for len(c.tamanhosBusca) <= n {
+			c.tamanhosBusca = append(c.tamanhosBusca, nil)
+		}
+		c.tamanhosBusca[n] = append(c.tamanhosBusca[n], int32(i))
+	}
+}
+
+// TermoEncontrado relaciona um termo da consulta ao termo do nome do artista
+// ou da música com o qual casou. Quando o tipo é TERMO_APROXIMADO, o termo
+// encontrado é a correção sugerida para o termo da consulta.
+type TermoEncontrado struct {
+	Consulta   string `json:"consulta"`
+	Encontrado string `json:"encontrado"`
+	Tipo       string `json:"tipo"`
+
+	peso float64
+}
+
+// ResultadoBusca é uma música encontrada pela busca textual.
+type ResultadoBusca struct {
+	Musica *Musica
+	// Termos encontrados, na ordem da consulta.
+	Termos []TermoEncontrado
+	// Média dos pesos dos termos encontrados (1 quando todos são exatos) e
+	// sua combinação com a popularidade da música.
+	Relevancia float64
+	Score      float64
+}
+
+// variantes retorna os termos do índice que casam com o termo da consulta,
+// do maior para o menor peso. O prefixo só é considerado no último termo.
+func (c *Catalog) variantes(termo string, ultimo bool) []TermoEncontrado {
+	pesos := make(map[string]TermoEncontrado)
+	adiciona := func(t TermoEncontrado) {
+		if atual, ok := pesos[t.Encontrado]; !ok || t.peso > atual.peso {
+			pesos[t.Encontrado] = t
+		}
+	}
+	if _, ok := c.indiceBusca[termo]; ok {
+		adiciona(TermoEncontrado{termo, termo, TERMO_EXATO, 1})
+	}
+	runas := []rune(termo)
+	if ultimo {
+		for i := sort.SearchStrings(c.vocabularioBusca, termo); i < len(c.vocabularioBusca) && strings.HasPrefix(c.vocabularioBusca[i], termo); i++ {
+			if t := c.vocabularioBusca[i]; t != termo {
+				peso := PESO_PREFIXO * float64(len(runas)) / float64(len(c.runasBusca[i]))
+				adiciona(TermoEncontrado{termo, t, TERMO_PREFIXO, peso})
+			}
+		}
+	}
+	if max := maxDistancia(len(runas)); max > 0 {
+		// Apenas termos com diferença de tamanho até max podem estar a essa
+		// distância.
+		var e edicao
+		for n := len(runas) - max; n <= len(runas)+max && n < len(c.tamanhosBusca); n++ {
+			for _, i := range c.tamanhosBusca[n] {
+				if d := e.distancia(runas, c.runasBusca[i], max); d > 0 && d <= max {
+					adiciona(TermoEncontrado{termo, c.vocabularioBusca[i], TERMO_APROXIMADO, 1 / float64(1+d)})
+				}
+			}
+		}
+	}
+	variantes := make([]TermoEncontrado, 0, len(pesos))
+	for _, t := range pesos {
+		variantes = append(variantes, t)
+	}
+	sort.Slice(variantes, func(i, j int) bool {
+		if variantes[i].peso != variantes[j].peso {
+			return variantes[i].peso > variantes[j].peso
+		}
+		return variantes[i].Encontrado < variantes[j].Encontrado
+	})
+	return variantes
+}
+
+// Busca retorna as músicas que possuem todos os termos da consulta no nome do
+// artista ou da música. Os termos são comparados sem distinção de maiúsculas
+// e acentos, admitindo erros de digitação (veja maxDistancia) e, no último
+// termo, apenas o início da palavra. As músicas são ordenadas pelo score, que
+// combina a relevância dos termos encontrados com a popularidade. Apenas
+// músicas dos gêneros passados são consideradas (todas, caso nenhum gênero
+// seja passado).
+func (c *Catalog) Busca(consulta string, generos sets.Set) []ResultadoBusca {
+	termos := termosBusca(consulta)
+	if len(termos) == 0 {
+		return nil
+	}
+	// Para cada termo da consulta, o índice da melhor variante presente em
+	// cada música.
+	melhores := make([]map[int32]int, len(termos))
+	variantes := make([][]TermoEncontrado, len(termos))
+	for i, t := range termos {
+		variantes[i] = c.variantes(t, i == len(termos)-1)
+		melhores[i] = make(map[int32]int)
+		for v := len(variantes[i]) - 1; v >= 0; v-- {
+			for _, p := range c.indiceBusca[variantes[i][v].Encontrado] {
+				melhores[i][p] = v
+			}
+		}
+		if len(melhores[i]) == 0 {
+			return nil
+		}
+	}
+	// A interseção parte do termo presente em menos músicas.
+	menor := 0
+	for i := range melhores {
+		if len(melhores[i]) < len(melhores[menor]) {
+			menor = i
+		}
+	}
+
+	var porGenero bitset
+	if generos.Cardinality() > 0 {
+		porGenero = c.bitsGeneros(generos)
+	}
+	maxPopularidade := math.Log1p(float64(c.musicas[0].Popularidade))
+	var resultados []ResultadoBusca
+	var posicoes []int32
+	for p := range melhores[menor] {
+		if porGenero != nil && !porGenero.contem(int(p)) {
+			continue
+		}
+		r := ResultadoBusca{Musica: c.musicas[p]}
+		for i := range termos {
+			v, ok := melhores[i][p]
+			if !ok {
+				break
+			}
+			r.Termos = append(r.Termos, variantes[i][v])
+			r.Relevancia += variantes[i][v].peso
+		}
+		if len(r.Termos) < len(termos) {
+			continue
+		}
+		for i := range r.Termos {
+			r.Termos[i].Encontrado = c.grafiasBusca[r.Termos[i].Encontrado]
+		}
+		r.Relevancia /= float64(len(termos))
+		r.Score = (1 - PESO_POPULARIDADE) * r.Relevancia
+		if maxPopularidade > 0 {
+			r.Score += PESO_POPULARIDADE * math.Log1p(float64(r.Musica.Popularidade)) / maxPopularidade
+		}
+		resultados = append(resultados, r)
+		posicoes = append(posicoes, p)
+	}
+	sort.Sort(porScoreBusca{resultados, posicoes})
+	return resultados
+}
+
+// porScoreBusca ordena os resultados da busca pelo score. Empates são
+// desfeitos pela posição das músicas no catálogo (por popularidade).
+type porScoreBusca struct {
+	resultados []ResultadoBusca
+	posicoes   []int32
+}
+
+func (p porScoreBusca) Len() int { return len(p.resultados) }
+func (p porScoreBusca) Swap(i, j int) {
+	p.resultados[i], p.resultados[j] = p.resultados[j], p.resultados[i]
+	p.posicoes[i], p.posicoes[j] = p.posicoes[j], p.posicoes[i]
+}
+func (p porScoreBusca) Less(i, j int) bool {
+	if p.resultados[i].Score != p.resultados[j].Score {
+		return p.resultados[i].Score > p.resultados[j].Score
+	}
+	return p.posicoes[i] < p.posicoes[j]
+}
+
+// edicao guarda as linhas da tabela do cálculo da distância de edição,
+// reaproveitadas entre os cálculos de uma mesma consulta.
+type edicao struct {
+	anterior, atual []int
+}
+
+// distancia retorna a distância de edição (Levenshtein) entre a e b, ou
+// max+1 caso ela seja maior que max.
+func (e *edicao) distancia(a, b []rune, max int) int {
+	if len(a)-len(b) > max || len(b)-len(a) > max {
+		return max + 1
+	}
+	if cap(e.anterior) < len(b)+1 {
+		e.anterior, e.atual = make([]int, len(b)+1), make([]int, len(b)+1)
+	}
+	anterior, atual := e.anterior[:len(b)+1], e.atual[:len(b)+1]
+	for j := range anterior {
+		anterior[j] = j
+	}
+	for i := 1; i <= len(a); i++ {
+		atual[0] = i
+		menor := i
+		for j := 1; j <= len(b); j++ {
+			custo := 1
+			if a[i-1] == b[j-1] {
+				custo = 0
+			}
+			atual[j] = anterior[j-1] + custo
+			if anterior[j]+1 < atual[j] {
+				atual[j] = anterior[j] + 1
+			}
+			if atual[j-1]+1 < atual[j] {
+				atual[j] = atual[j-1] + 1
+			}
+			if atual[j] < menor {
+				menor = atual[j]
+			}
+		}
+		// A distância não diminui nas linhas seguintes.
+		if menor > max {
+			return max + 1
+		}
+		anterior, atual = atual, anterior
+	}
+	if anterior[len(b)] > max {
+		return max + 1
+	}
+	return anterior[len(b)]
+}
@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"testing"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+// buscaLinear reproduz a busca textual por termos exatos feita sobre todas as
+// músicas a cada consulta, usada como referência nos benchmarks.
+func buscaLinear(c *Catalog, consulta string, generos sets.Set) []*Musica {
+	keys := strings.Split(RemoverCombinantes(strings.ToLower(consulta)), " ")
+	var musicas []*Musica
+	for _, m := range c.Filtra(generos) {
+		text := fmt.Sprintf("%s %s", strings.ToLower(m.Artista), strings.ToLower(m.Nome))
+		toCheck := make(map[string]struct{})
+		for _, t := range strings.Split(RemoverCombinantes(text), " ") {
+			toCheck[t] = struct{}{}
+		}
+		todas := true
+		for _, k := range keys {
+			if _, ok := toCheck[k]; !ok {
+				todas = false
+				break
+			}
+		}
+		if todas {
+			musicas = append(musicas, m)
+		}
+	}
+	sort.Sort(PorPopularidade(musicas))
+	return musicas
+}
+
+var buscasBenchmark = []struct {
+	nome, consulta string
+	generos        []string
+}{
+	{"comum", "amor", nil},
+	{"comuns", "o amor de voce", nil},
+	{"comuns_genero", "o amor de voce", []string{"Rock", "MPB"}},
+	{"rara", "violeiro poeira", nil},
+	{"ausente", "legiao urbana", nil},
+	{"aproximada", "saudde do sertao", nil},
+	{"prefixo", "amor de vi", nil},
+}
+
+func BenchmarkBusca(b *testing.B) {
+	c := catalogSintetico(20000, palavrasSinteticas)
+	for _, q := range buscasBenchmark {
+		generos := sets.NewSet()
+		for _, g := range q.generos {
+			generos.Add(g)
+		}
+		// A busca por termos exatos deve encontrar todas as músicas da busca
+		// linear, além das encontradas por prefixo e por aproximação.
+		encontradas := make(map[*Musica]bool)
+		for _, r := range c.Busca(q.consulta, generos) {
+			encontradas[r.Musica] = true
+		}
+		for _, m := range buscaLinear(c, q.consulta, generos) {
+			if !encontradas[m] {
+				b.Fatalf("%s: índice não encontrou %s", q.nome, m.UniqueID)
+			}
+		}
+		b.Run(q.nome+"/linear", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				buscaLinear(c, q.consulta, generos)
+			}
+		})
+		b.Run(q.nome+"/indice", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Busca(q.consulta, generos)
+			}
+		})
+	}
+}
+
+func BenchmarkSugestoes(b *testing.B) {
+	c := catalogSintetico(20000, palavrasSinteticas)
+	for _, q := range []struct {
+		nome, consulta string
+		generos        []string
+	}{
+		{"uma_letra", "a", nil},
+		{"palavra", "amo", nil},
+		{"palavra_genero", "amo", []string{"Forró"}},
+		{"nome", "saudade do s", nil},
+	} {
+		generos := sets.NewSet()
+		for _, g := range q.generos {
+			generos.Add(g)
+		}
+		b.Run(q.nome, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Sugestoes(q.consulta, generos, NUM_SUGESTOES)
+			}
+		})
+	}
+}
+
+func BenchmarkBuscaAproximada(b *testing.B) {
+	c := catalogSintetico(40000, vocabularioSintetico(30000))
+	b.Logf("%d termos no vocabulário", len(c.vocabularioBusca))
+	for _, q := range []struct{ nome, consulta string }{
+		{"curto", "mala"},
+		{"medio", "caçaropa"},
+		{"longo", "ventarolibara"},
+		{"dois_termos", "lemavi taropi"},
+	} {
+		b.Run(q.nome, func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Busca(q.consulta, sets.NewSet())
+			}
+		})
+	}
+}
+
+func TestDistancia(t *testing.T) {
+	var e edicao
+	for _, c := range []struct {
+		a, b      string
+		max, want int
+	}{
+		{"saudade", "saudade", 2, 0},
+		{"saudde", "saudade", 2, 1},
+		{"sertao", "sertão", 1, 1},
+		{"amor", "ramo", 2, 2},
+		{"amor", "ramo", 1, 2},
+		{"coracao", "cora", 2, 3},
+		{"a", "", 1, 1},
+	} {
+		if got := e.distancia([]rune(c.a), []rune(c.b), c.max); got != c.want {
+			t.Errorf("distancia(%q, %q, %d) = %d, want %d", c.a, c.b, c.max, got, c.want)
+		}
+	}
+}
+
+// Os termos aproximados encontrados pelos grupos de tamanho devem ser os
+// mesmos encontrados percorrendo todo o vocabulário.
+func TestVariantesAproximadas(t *testing.T) {
+	c := catalogSintetico(2000, vocabularioSintetico(1500))
+	var e edicao
+	for _, consulta := range []string{"mala", "caçaropa", "lemavi", "ventarolibara", "sabe"} {
+		termo := termosBusca(consulta)[0]
+		runas := []rune(termo)
+		esperados := make(map[string]bool)
+		for i, v := range c.runasBusca {
+			if d := e.distancia(runas, v, maxDistancia(len(runas))); d > 0 && d <= maxDistancia(len(runas)) {
+				esperados[c.vocabularioBusca[i]] = true
+			}
+		}
+		encontrados := 0
+		for _, v := range c.variantes(termo, false) {
+			if v.Tipo != TERMO_APROXIMADO {
+				continue
+			}
+			encontrados++
+			if !esperados[v.Encontrado] {
+				t.Errorf("%s: %s não deveria ser encontrado", consulta, v.Encontrado)
+			}
+		}
+		if encontrados != len(esperados) {
+			t.Errorf("%s: %d termos aproximados, want %d", consulta, encontrados, len(esperados))
+		}
+	}
+}
@@ -0,0 +1,303 @@
+// Package catalog mantém o conjunto de músicas do ciframe e os índices
+// utilizados pelas buscas (por id, gênero e acorde).
+package catalog
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"io"
+	"os"
+	"sort"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	sets "github.com/deckarep/golang-set"
+)
+
+const (
+	TAM_PAGINA = 100
+)
+
+// Catalog armazena as músicas carregadas do dataset e seus índices.
+// Depois de construído, um Catalog não é mais alterado e pode ser
+// compartilhado entre goroutines.
+type Catalog struct {
+	versao      string
+	acordes     []string
+	musicasDict map[string]*Musica // Mapa de músicas indexado por ids únicos.
+	generosSet  sets.Set
+	musicas     []*Musica // todas as músicas, ordenadas por popularidade.
+
+	// Número de ocorrências de cada trecho de cifra que não é um acorde válido.
+	acordesInvalidos map[string]int
+	// Linhas do dataset ignoradas por serem inválidas.
+	linhasInvalidas []*ErroLinha
+
+	// Os conjuntos contém ids das músicas. Os acordes são indexados pela
+	// forma enarmônica canônica.
+	musicasPorAcorde map[string]sets.Set
+	musicasPorGenero map[string]sets.Set
+
+	// Uso de cada acorde (na forma enarmônica) por gênero e soma das
+	// popularidades das músicas de cada gênero.
+	usoAcordes            map[string]*usoAcorde
+	popularidadePorGenero map[string]int
+
+	// Nome de cada gênero indexado pela chave normalizada, grafias do dataset
+	// agrupadas em cada gênero e estatísticas dos gêneros, ordenados pelo nome.
+	generosPorChave     map[string]string
+	variantesGenero     map[string][]string
+	estatisticasGeneros []*EstatisticaGenero
+
+	// Posições das músicas que possuem cada termo no nome do artista ou da
+	// música, usadas pela busca textual, a grafia acentuada de cada termo e
+	// os termos em ordem alfabética (também como runas). tamanhosBusca[n]
+	// contém as posições no vocabulário dos termos com n letras.
+	indiceBusca      map[string][]int32
+	grafiasBusca     map[string]string
+	vocabularioBusca []string
+	runasBusca       [][]rune
+	tamanhosBusca    [][]int32
+
+	// Sugestões do autocompletar, ordenadas por popularidade, e seus nomes
+	// normalizados, em ordem alfabética.
+	sugestoes []*Sugestao
+	prefixos  []prefixo
+
+	// Artistas ordenados pela soma das popularidades das músicas, e
+	// indexados por id.
+	artistas     []*Artista
+	artistasDict map[string]*Artista
+
+	// musicasPorRaro[i] contém, para cada acorde do dicionário enarmônico,
+	// as posições das músicas nas quais ele é o i-ésimo acorde menos
+	// frequente no catálogo. Usado na busca por músicas tocáveis.
+	musicasPorRaro [MAX_TOLERANCIA + 1][][]int32
+
+	// Dicionários de termos de cada forma de comparação e, para cada gênero,
+	// o bitset com as posições das suas músicas. Usados na busca por músicas
+	// similares.
+	dicionarios [numComparacoes]*dicionario
+	generosBits map[string]bitset
+
+	// Sequências famosas mineradas das cifras, ordenadas pelo número de
+	// músicas, e indexadas por id e pela chave independente do tom.
+	sequencias         []*Sequencia
+	sequenciasDict     map[string]*Sequencia
+	sequenciasPorChave map[uint64]*Sequencia
+	formasSequencias   formas
+}
+
+// New constrói um catálogo a partir do dataset lido de r. Linhas inválidas
+// são ignoradas e podem ser consultadas em LinhasInvalidas. Retorna erro
+// apenas caso o dataset não possa ser lido ou o cabeçalho seja inválido.
+func New(r io.Reader) (*Catalog, error) {
+	var musicas []*Musica
+	var invalidas []*ErroLinha
+	h := sha1.New()
+	d := NewReader(io.TeeReader(r, h))
+	for {
+		m, err := d.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			erroLinha, ok := err.(*ErroLinha)
+			if !ok || d.colunas == nil {
+				return nil, err
+			}
+			invalidas = append(invalidas, erroLinha)
+			continue
+		}
+		musicas = append(musicas, m)
+	}
+	c := novoCatalog(musicas)
+	c.versao = hex.EncodeToString(h.Sum(nil))[:12]
+	c.linhasInvalidas = invalidas
+	return c, nil
+}
+
+// FromFile constrói um catálogo a partir do dataset armazenado em path.
+func FromFile(path string) (*Catalog, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return New(f)
+}
+
+func novoCatalog(musicas []*Musica) *Catalog {
+	c := &Catalog{
+		musicasDict:      make(map[string]*Musica),
+		generosSet:       sets.NewSet(),
+		musicasPorAcorde: make(map[string]sets.Set),
+		musicasPorGenero: make(map[string]sets.Set),
+		acordesInvalidos: make(map[string]int),
+	}
+	// Grafias diferentes do mesmo gênero ("Sertanejo" e "sertanejo ") são
+	// tratadas como um único gênero.
+	c.normalizaGeneros(musicas)
+	acordesSet := sets.NewSet()
+	for _, musica := range musicas {
+		// inclui música no dict de músicas
+		c.musicasDict[musica.UniqueID] = musica
+
+		for _, a := range musica.AcordesInvalidos {
+			c.acordesInvalidos[a]++
+		}
+
+		// conjunto único de gêneros
+		c.generosSet.Add(musica.Genero)
+
+		// Acordes.
+		musica.indexaEnarmonicos()
+		// conjunto único de acordes
+		for a := range musica.Acordes().Iter() {
+			acordesSet.Add(a)
+		}
+		// Populando mapa de músicas por acorde.
+		for a := range musica.AcordesEnarmonicos().Iter() {
+			if _, ok := c.musicasPorAcorde[a.(string)]; !ok {
+				c.musicasPorAcorde[a.(string)] = sets.NewSet()
+			}
+			c.musicasPorAcorde[a.(string)].Add(musica.UniqueID)
+		}
+
+		// Tom estimado e graus dos acordes, para as buscas que independem do
+		// tom das músicas.
+		musica.estimaTom()
+		musica.indexaGraus()
+
+		// constrói dict mapeando gênero para músicas
+		// deve ser usado para melhorar o desempenho das buscas
+		if _, ok := c.musicasPorGenero[musica.Genero]; !ok {
+			c.musicasPorGenero[musica.Genero] = sets.NewSet()
+		}
+		c.musicasPorGenero[musica.Genero].Add(musica.UniqueID)
+
+		// popula lista com todas as músicas.
+		c.musicas = append(c.musicas, musica)
+	}
+
+	// Ordena todas as músicas por popularidade.
+	sort.Sort(PorPopularidade(c.musicas))
+
+	c.indexaArtistas()
+	c.indexaGeneros()
+	c.indexaBusca()
+	c.indexaSugestoes()
+	c.indexaUsoAcordes()
+	c.indexaBits()
+	c.indexaRaros()
+	c.indexaProgressoes()
+	c.mineraSequencias()
+
+	// transformando o conjunto único de acordes numa lista.
+	// melhor eficiência e melhor para trabalhar com json.
+	for a := range acordesSet.Iter() {
+		c.acordes = append(c.acordes, a.(string))
+	}
+	return c
+}
+
+// Versao identifica o conteúdo do dataset a partir do qual o catálogo foi
+// construído. Catálogos construídos a partir do mesmo dataset possuem a mesma
+// versão.
+func (c *Catalog) Versao() string {
+	return c.versao
+}
+
+// Musica retorna a música identificada pelo id único.
+func (c *Catalog) Musica(id string) (*Musica, bool) {
+	m, ok := c.musicasDict[id]
+	return m, ok
+}
+
+// Musicas retorna todas as músicas, ordenadas por popularidade.
+func (c *Catalog) Musicas() []*Musica {
+	return c.musicas
+}
+
+// NumMusicas retorna o número de músicas do catálogo.
+func (c *Catalog) NumMusicas() int {
+	return len(c.musicas)
+}
+
+// Pagina retorna a página (começando em 1) da lista de músicas ordenada
+// por popularidade.
+func (c *Catalog) Pagina(pagina int) []*Musica {
+	i, f := LimitesDaPagina(len(c.musicas), pagina)
+	return c.musicas[i:f]
+}
+
+// Acordes retorna todos os acordes presentes no catálogo.
+func (c *Catalog) Acordes() []string {
+	return c.acordes
+}
+
+// AcordesInvalidos retorna os trechos das cifras que não puderam ser
+// interpretados como acordes e o número de ocorrências de cada um.
+func (c *Catalog) AcordesInvalidos() map[string]int {
+	return c.acordesInvalidos
+}
+
+// LinhasInvalidas retorna os problemas das linhas do dataset que foram
+// ignoradas na construção do catálogo.
+func (c *Catalog) LinhasInvalidas() []*ErroLinha {
+	return c.linhasInvalidas
+}
+
+// Generos retorna o conjunto de gêneros presentes no catálogo.
+func (c *Catalog) Generos() sets.Set {
+	return c.generosSet
+}
+
+// PorAcorde retorna os ids das músicas que possuem o acorde, considerando
+// grafias enarmônicas como o mesmo acorde. O conjunto retornado não deve ser
+// alterado.
+func (c *Catalog) PorAcorde(a string) (sets.Set, bool) {
+	chave, err := acorde.ChaveEnarmonica(a)
+	if err != nil {
+		return nil, false
+	}
+	s, ok := c.musicasPorAcorde[chave]
+	return s, ok
+}
+
+// PorGenero retorna os ids das músicas do gênero. O conjunto retornado
+// não deve ser alterado.
+func (c *Catalog) PorGenero(genero string) (sets.Set, bool) {
+	s, ok := c.musicasPorGenero[genero]
+	return s, ok
+}
+
+// Filtra retorna as músicas que pertencem a algum dos gêneros passados.
+// Caso nenhum gênero seja passado, retorna todas as músicas.
+func (c *Catalog) Filtra(generos sets.Set) []*Musica {
+	if generos.Cardinality() == 0 {
+		return c.musicas
+	}
+	var collection []*Musica
+	for g := range generos.Iter() {
+		if c.generosSet.Contains(g.(string)) {
+			for m := range c.musicasPorGenero[g.(string)].Iter() {
+				collection = append(collection, c.musicasDict[m.(string)])
+			}
+		}
+	}
+	return collection
+}
+
+// LimitesDaPagina retorna os índices [inicio, fim) da página (começando em 1)
+// em uma lista de tamanho size. Páginas fora da lista são vazias.
+func LimitesDaPagina(size int, pagina int) (int, int) {
+	i := (pagina - 1) * TAM_PAGINA
+	if pagina < 1 || i > size {
+		return size, size
+	}
+	f := i + TAM_PAGINA
+	if f > size {
+		f = size
+	}
+	return i, f
+}
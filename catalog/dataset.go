@@ -0,0 +1,244 @@
+package catalog
+
+import (
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+)
+
+// Nomes das colunas do dataset.
+const (
+	ARTISTA_ID   = "ARTISTA_ID"
+	MUSICA_ID    = "MUSICA_ID"
+	ARTISTA      = "ARTISTA"
+	MUSICA       = "MUSICA"
+	GENERO       = "GENERO"
+	POPULARIDADE = "POPULARIDADE"
+	TOM          = "TOM"
+	SEQ_FAMOSA   = "SEQ_FAMOSA"
+	CIFRA        = "CIFRA"
+)
+
+// Ordem das colunas quando o dataset não possui cabeçalho.
+var colunasPadrao = []string{ARTISTA_ID, MUSICA_ID, ARTISTA, MUSICA, GENERO, POPULARIDADE, TOM, SEQ_FAMOSA, CIFRA}
+
+// Colunas que precisam estar presentes no dataset. As demais são opcionais.
+var colunasObrigatorias = []string{ARTISTA_ID, MUSICA_ID, ARTISTA, MUSICA, GENERO, POPULARIDADE, CIFRA}
+
+// Valor utilizado no dataset para indicar campos nulos.
+const valorNulo = "NA"
+
+// ErroLinha descreve um problema encontrado em uma linha do dataset.
+type ErroLinha struct {
+	Linha int
+	Err   error
+}
+
+func (e *ErroLinha) Error() string {
+	return fmt.Sprintf("linha %d: %v", e.Linha, e.Err)
+}
+
+// Reader lê músicas de um dataset no formato CSV (RFC 4180).
+//
+// As colunas são mapeadas pelo nome definido na primeira linha (cabeçalho).
+// Caso a primeira linha não seja um cabeçalho, as colunas são lidas na ordem
+// padrão: ARTISTA_ID, MUSICA_ID, ARTISTA, MUSICA, GENERO, POPULARIDADE, TOM,
+// SEQ_FAMOSA e CIFRA. Campos cujo conteúdo é exatamente NA são tratados como
+// vazios.
+type Reader struct {
+	r       *csv.Reader
+	colunas map[string]int
+	// primeira linha de dados, lida durante a detecção do cabeçalho.
+	pendente []string
+	err      error
+}
+
+// NewReader retorna um Reader que lê o dataset de r.
+func NewReader(r io.Reader) *Reader {
+	csvReader := csv.NewReader(r)
+	// O número de campos é verificado por Read, para que linhas curtas sejam
+	// reportadas com o nome da coluna ausente.
+	csvReader.FieldsPerRecord = -1
+	return &Reader{r: csvReader}
+}
+
+// Read retorna a próxima música do dataset. Ao final do dataset, retorna
+// io.EOF. Problemas em uma linha são reportados como *ErroLinha e não
+// impedem a leitura das linhas seguintes.
+func (d *Reader) Read() (*Musica, error) {
+	if d.colunas == nil && d.err == nil {
+		d.err = d.lerCabecalho()
+	}
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	var registro []string
+	if d.pendente != nil {
+		registro, d.pendente = d.pendente, nil
+	} else {
+		var err error
+		registro, err = d.r.Read()
+		if err != nil {
+			return nil, erroCSV(err)
+		}
+	}
+	m, err := d.musica(registro)
+	if err != nil {
+		return nil, &ErroLinha{Linha: d.Linha(), Err: err}
+	}
+	return m, nil
+}
+
+// Linha retorna o número da linha (começando em 1) do último registro lido.
+func (d *Reader) Linha() int {
+	linha, _ := d.r.FieldPos(0)
+	return linha
+}
+
+func (d *Reader) lerCabecalho() error {
+	cabecalho, err := d.r.Read()
+	if err != nil {
+		return erroCSV(err)
+	}
+	colunas := make(map[string]int)
+	for i, nome := range cabecalho {
+		colunas[strings.ToUpper(strings.TrimSpace(nome))] = i
+	}
+	if _, ok := colunas[ARTISTA_ID]; !ok {
+		// Sem cabeçalho: a primeira linha já contém dados.
+		d.pendente = cabecalho
+		d.colunas = make(map[string]int)
+		for i, nome := range colunasPadrao {
+			d.colunas[nome] = i
+		}
+		return nil
+	}
+	for _, nome := range colunasObrigatorias {
+		if _, ok := colunas[nome]; !ok {
+			return &ErroLinha{Linha: 1, Err: fmt.Errorf("coluna %s ausente no cabeçalho", nome)}
+		}
+	}
+	d.colunas = colunas
+	return nil
+}
+
+// campo retorna o valor da coluna no registro. Colunas opcionais ausentes
+// do dataset são tratadas como vazias.
+func (d *Reader) campo(registro []string, nome string) (string, error) {
+	i, ok := d.colunas[nome]
+	if !ok {
+		return "", nil
+	}
+	if i >= len(registro) {
+		return "", fmt.Errorf("coluna %s ausente (a linha possui %d campos)", nome, len(registro))
+	}
+	if registro[i] == valorNulo {
+		return "", nil
+	}
+	return registro[i], nil
+}
+
+func (d *Reader) musica(registro []string) (*Musica, error) {
+	dados := make(map[string]string, len(colunasPadrao))
+	for _, nome := range colunasPadrao {
+		v, err := d.campo(registro, nome)
+		if err != nil {
+			return nil, err
+		}
+		dados[nome] = v
+	}
+	musica := Musica{
+		Artista:    dados[ARTISTA],
+		IDArtista:  dados[ARTISTA_ID],
+		ID:         dados[MUSICA_ID],
+		Nome:       dados[MUSICA],
+		Genero:     dados[GENERO],
+		Tom:        dados[TOM],
+		UniqueID:   UniqueID(dados[ARTISTA_ID], dados[MUSICA_ID]),
+		URL:        URL(dados[ARTISTA_ID], dados[MUSICA_ID]),
+		SeqFamosas: splitLista(dados[SEQ_FAMOSA]),
+	}
+
+	var err error
+	musica.Popularidade, err = strconv.Atoi(strings.Replace(dados[POPULARIDADE], ".", "", -1))
+	if err != nil {
+		return nil, fmt.Errorf("%s inválida: %q", POPULARIDADE, dados[POPULARIDADE])
+	}
+
+	musica.Cifra = []string{}
+	if dados[CIFRA] != "" {
+		musica.Cifra, musica.Estruturas, musica.AcordesInvalidos = canonizaCifra(limpaCifra(strings.Split(dados[CIFRA], ";")))
+	}
+	return &musica, nil
+}
+
+// erroCSV converte erros de parse do pacote csv em *ErroLinha.
+func erroCSV(err error) error {
+	var parseErr *csv.ParseError
+	if errors.As(err, &parseErr) {
+		return &ErroLinha{Linha: parseErr.StartLine, Err: parseErr.Err}
+	}
+	return err
+}
+
+// splitLista separa os valores de um campo separado por ponto e vírgula.
+func splitLista(s string) []string {
+	lista := []string{}
+	for _, v := range strings.Split(s, ";") {
+		if v = strings.TrimSpace(v); v != "" {
+			lista = append(lista, v)
+		}
+	}
+	return lista
+}
+
+func limpaCifra(rawCifra []string) []string {
+	var cifra []string
+	for _, m := range rawCifra {
+		m = strings.Trim(m, " ")
+		if len(m) != 0 {
+			if strings.Contains(m, "|") {
+				// filtra tablaturas
+				acorde := strings.Split(m, "|")[0]
+				acorde = pythonSplit(acorde)[0]
+				cifra = append(cifra, acorde)
+			} else {
+				// lida com acordes separados por espaço
+				cifra = append(cifra, pythonSplit(m)...)
+			}
+		}
+	}
+	return cifra
+}
+
+// canonizaCifra converte os acordes da cifra para sua forma canônica e
+// retorna também a estrutura de cada um. Os trechos que não são acordes
+// válidos são retornados separadamente.
+func canonizaCifra(tokens []string) (cifra []string, estruturas []acorde.Acorde, invalidos []string) {
+	cifra = []string{}
+	for _, t := range tokens {
+		a, err := acorde.Parse(t)
+		if err != nil {
+			invalidos = append(invalidos, t)
+			continue
+		}
+		cifra = append(cifra, a.String())
+		estruturas = append(estruturas, a)
+	}
+	return cifra, estruturas, invalidos
+}
+
+// Mais perto que consegui da função split() em python.
+// A idéia é converter múltiplos espaços consecutivos em um espaço e então fazer split.
+var multiplosEspacos = regexp.MustCompile(" +")
+
+func pythonSplit(s string) []string {
+	return strings.Split(multiplosEspacos.ReplaceAllString(s, " "), " ")
+}
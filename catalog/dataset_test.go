@@ -0,0 +1,36 @@
+package catalog
+
+import (
+	"strings"
+	"testing"
+)
+
+const datasetTeste = `ARTISTA_ID,MUSICA_ID,ARTISTA,MUSICA,GENERO,POPULARIDADE,TOM,SEQ_FAMOSA,CIFRA
+legiao-urbana,tempo-perdido,Legião Urbana,Tempo Perdido,Rock,1.500,C,1,C;G;Am;F
+a,b,c
+legiao-urbana,pais-e-filhos,Legião Urbana,Pais e Filhos,Rock,mil,G,NA,G;D;Em;C
+titas,epitafio,Titãs,Epitáfio,Rock,900,"C,NA,C;Em;F
+`
+
+func TestNewIgnoraLinhasInvalidas(t *testing.T) {
+	c, err := New(strings.NewReader(datasetTeste))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := c.Musica("legiao-urbana_tempo-perdido"); !ok {
+		t.Error("música válida não foi carregada")
+	}
+	var linhas []int
+	for _, e := range c.LinhasInvalidas() {
+		linhas = append(linhas, e.Linha)
+	}
+	if len(linhas) != 3 || linhas[0] != 3 || linhas[1] != 4 || linhas[2] != 5 {
+		t.Errorf("LinhasInvalidas() nas linhas %v, want [3 4 5]", linhas)
+	}
+}
+
+func TestNewCabecalhoInvalido(t *testing.T) {
+	if _, err := New(strings.NewReader("ARTISTA_ID,MUSICA\nx,y\n")); err == nil {
+		t.Error("New com cabeçalho inválido: want erro")
+	}
+}
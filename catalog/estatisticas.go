@@ -0,0 +1,166 @@
+package catalog
+
+import (
+	"sort"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	sets "github.com/deckarep/golang-set"
+)
+
+// Número de gêneros nas estatísticas de cada acorde.
+const NUM_GENEROS_ACORDE = 3
+
+// EstatisticaAcorde descreve o uso de um acorde nas músicas do catálogo.
+type EstatisticaAcorde struct {
+	// Grafia mais usada do acorde (A# e Bb são contados juntos).
+	Acorde string `json:"acorde"`
+	// Número de músicas que usam o acorde.
+	Musicas int `json:"musicas"`
+	// Soma das popularidades das músicas que usam o acorde sobre a soma das
+	// popularidades de todas as músicas consideradas.
+	FrequenciaPonderada float64 `json:"frequencia_ponderada"`
+	// Gêneros com mais músicas que usam o acorde.
+	Generos []Frequencia `json:"generos"`
+	// Estrutura da grafia mais usada.
+	Estrutura acorde.Acorde `json:"-"`
+}
+
+// usoAcorde guarda, para cada gênero, o número de músicas que usam um acorde
+// e a soma das suas popularidades.
+type usoAcorde struct {
+	grafia    string
+	estrutura acorde.Acorde
+	musicas   map[string]int
+	populares map[string]int
+}
+
+// indexaUsoAcordes calcula o uso de cada acorde (na forma enarmônica) por
+// gênero, a partir dos índices de músicas por acorde e por gênero.
+func (c *Catalog) indexaUsoAcordes() {
+	c.usoAcordes = make(map[string]*usoAcorde)
+	c.popularidadePorGenero = make(map[string]int)
+	for g, ids := range c.musicasPorGenero {
+		for id := range ids.Iter() {
+			c.popularidadePorGenero[g] += c.musicasDict[id.(string)].Popularidade
+		}
+	}
+	for chave, ids := range c.musicasPorAcorde {
+		u := &usoAcorde{musicas: make(map[string]int), populares: make(map[string]int)}
+		grafias := make(map[string]int)
+		exemplos := make(map[string]*Musica)
+		for id := range ids.Iter() {
+			m := c.musicasDict[id.(string)]
+			u.musicas[m.Genero]++
+			u.populares[m.Genero] += m.Popularidade
+			g := m.grafia(chave)
+			grafias[g]++
+			exemplos[g] = m
+		}
+		for g, n := range grafias {
+			if n > grafias[u.grafia] || n == grafias[u.grafia] && g < u.grafia {
+				u.grafia = g
+			}
+		}
+		u.estrutura, _ = exemplos[u.grafia].Estrutura(u.grafia)
+		c.usoAcordes[chave] = u
+	}
+}
+
+// estatistica calcula as estatísticas do acorde considerando apenas os
+// gêneros passados (todos, caso nenhum seja passado). popularidade é a soma
+// das popularidades das músicas desses gêneros.
+func (u *usoAcorde) estatistica(generos sets.Set, popularidade int) *EstatisticaAcorde {
+	e := &EstatisticaAcorde{Acorde: u.grafia, Estrutura: u.estrutura, Generos: []Frequencia{}}
+	populares := 0
+	for g, n := range u.musicas {
+		if generos.Cardinality() > 0 && !generos.Contains(g) {
+			continue
+		}
+		e.Musicas += n
+		populares += u.populares[g]
+		e.Generos = append(e.Generos, Frequencia{g, n})
+	}
+	if popularidade > 0 {
+		e.FrequenciaPonderada = float64(populares) / float64(popularidade)
+	}
+	sort.Slice(e.Generos, func(i, j int) bool {
+		if e.Generos[i].Musicas != e.Generos[j].Musicas {
+			return e.Generos[i].Musicas > e.Generos[j].Musicas
+		}
+		return e.Generos[i].Valor < e.Generos[j].Valor
+	})
+	if len(e.Generos) > NUM_GENEROS_ACORDE {
+		e.Generos = e.Generos[:NUM_GENEROS_ACORDE]
+	}
+	return e
+}
+
+// popularidade retorna a soma das popularidades das músicas dos gêneros
+// passados (todos, caso nenhum seja passado).
+func (c *Catalog) popularidade(generos sets.Set) int {
+	total := 0
+	for g, p := range c.popularidadePorGenero {
+		if generos.Cardinality() == 0 || generos.Contains(g) {
+			total += p
+		}
+	}
+	return total
+}
+
+// EstatisticasAcordes retorna as estatísticas de uso de todos os acordes nas
+// músicas dos gêneros passados (todas, caso nenhum seja passado), ordenadas
+// pelo número de músicas. Acordes que não aparecem nesses gêneros não são
+// retornados.
+func (c *Catalog) EstatisticasAcordes(generos sets.Set) []*EstatisticaAcorde {
+	popularidade := c.popularidade(generos)
+	estatisticas := []*EstatisticaAcorde{}
+	for _, u := range c.usoAcordes {
+		if e := u.estatistica(generos, popularidade); e.Musicas > 0 {
+			estatisticas = append(estatisticas, e)
+		}
+	}
+	sort.Slice(estatisticas, func(i, j int) bool {
+		if estatisticas[i].Musicas != estatisticas[j].Musicas {
+			return estatisticas[i].Musicas > estatisticas[j].Musicas
+		}
+		return estatisticas[i].Acorde < estatisticas[j].Acorde
+	})
+	return estatisticas
+}
+
+// EstatisticaAcorde retorna as estatísticas de uso do acorde (em qualquer
+// grafia) nas músicas dos gêneros passados.
+func (c *Catalog) EstatisticaAcorde(a string, generos sets.Set) (*EstatisticaAcorde, bool) {
+	chave, err := acorde.ChaveEnarmonica(a)
+	if err != nil {
+		return nil, false
+	}
+	u, ok := c.usoAcordes[chave]
+	if !ok {
+		return nil, false
+	}
+	return u.estatistica(generos, c.popularidade(generos)), true
+}
+
+// MusicasComAcorde retorna as músicas dos gêneros passados (todas, caso
+// nenhum seja passado) que usam o acorde, em qualquer grafia, ordenadas por
+// popularidade.
+func (c *Catalog) MusicasComAcorde(a string, generos sets.Set) []*Musica {
+	chave, err := acorde.ChaveEnarmonica(a)
+	if err != nil {
+		return nil
+	}
+	d := c.dicionarios[COMPARA_ENARMONICO]
+	n, ok := d.numeros[chave]
+	if !ok {
+		return nil
+	}
+	var musicas []*Musica
+	for _, p := range d.musicas[n] {
+		m := c.musicas[p]
+		if generos.Cardinality() == 0 || generos.Contains(m.Genero) {
+			musicas = append(musicas, m)
+		}
+	}
+	return musicas
+}
@@ -0,0 +1,109 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+// A soma das popularidades é 1200, das quais 300 em MPB.
+var musicasEstatisticas = []string{
+	"e,a,E,A,Rock,400,C,NA,C;G;Am;F",
+	"e,b,E,B,Rock,300,F,NA,Bb;F;C",
+	"e,c,E,C,MPB,200,Dm,NA,A#;F;Dm",
+	"e,d,E,D,MPB,100,C,NA,C;Em",
+	"e,f,E,F,Samba,100,D,NA,D;A",
+	"e,g,E,G,Forró,100,C,NA,C;A",
+	"e,h,E,H,Rock,0,Bb,NA,Bb;Eb",
+}
+
+func TestEstatisticaAcorde(t *testing.T) {
+	c := catalogTeste(t, musicasEstatisticas...)
+	for _, q := range []struct {
+		acorde  string
+		generos []string
+		want    EstatisticaAcorde
+	}{
+		// Empates entre gêneros são desfeitos pelo nome.
+		{"C", nil, EstatisticaAcorde{Acorde: "C", Musicas: 4, FrequenciaPonderada: 0.75,
+			Generos: []Frequencia{{"Rock", 2}, {"Forró", 1}, {"MPB", 1}}}},
+		{"C", []string{"MPB"}, EstatisticaAcorde{Acorde: "C", Musicas: 1, FrequenciaPonderada: 100.0 / 300,
+			Generos: []Frequencia{{"MPB", 1}}}},
+		// A# e Bb são contados juntos, com a grafia mais usada.
+		{"A#", nil, EstatisticaAcorde{Acorde: "Bb", Musicas: 3, FrequenciaPonderada: 500.0 / 1200,
+			Generos: []Frequencia{{"Rock", 2}, {"MPB", 1}}}},
+		{"Bb", []string{"Samba"}, EstatisticaAcorde{Acorde: "Bb", Generos: []Frequencia{}}},
+	} {
+		generos := sets.NewSet()
+		for _, g := range q.generos {
+			generos.Add(g)
+		}
+		got, ok := c.EstatisticaAcorde(q.acorde, generos)
+		if !ok {
+			t.Errorf("EstatisticaAcorde(%q, %v) não encontrada", q.acorde, q.generos)
+			continue
+		}
+		if got.Estrutura.String() != q.want.Acorde {
+			t.Errorf("EstatisticaAcorde(%q, %v).Estrutura = %v, want %s", q.acorde, q.generos, got.Estrutura, q.want.Acorde)
+		}
+		got.Estrutura = q.want.Estrutura
+		if !reflect.DeepEqual(*got, q.want) {
+			t.Errorf("EstatisticaAcorde(%q, %v) = %+v, want %+v", q.acorde, q.generos, *got, q.want)
+		}
+	}
+	for _, a := range []string{"Gm", "X"} {
+		if _, ok := c.EstatisticaAcorde(a, sets.NewSet()); ok {
+			t.Errorf("EstatisticaAcorde(%q) encontrada", a)
+		}
+	}
+}
+
+func TestEstatisticasAcordes(t *testing.T) {
+	c := catalogTeste(t, musicasEstatisticas...)
+	for _, q := range []struct {
+		generos []string
+		want    []Frequencia
+	}{
+		{nil, []Frequencia{{"C", 4}, {"Bb", 3}, {"F", 3}, {"A", 2}, {"Am", 1}, {"D", 1}, {"Dm", 1}, {"Eb", 1}, {"Em", 1}, {"G", 1}}},
+		// Acordes que não aparecem nos gêneros não são retornados.
+		{[]string{"MPB"}, []Frequencia{{"Bb", 1}, {"C", 1}, {"Dm", 1}, {"Em", 1}, {"F", 1}}},
+	} {
+		generos := sets.NewSet()
+		for _, g := range q.generos {
+			generos.Add(g)
+		}
+		var got []Frequencia
+		for _, e := range c.EstatisticasAcordes(generos) {
+			got = append(got, Frequencia{e.Acorde, e.Musicas})
+		}
+		if !reflect.DeepEqual(got, q.want) {
+			t.Errorf("EstatisticasAcordes(%v) = %v, want %v", q.generos, got, q.want)
+		}
+	}
+}
+
+func TestMusicasComAcorde(t *testing.T) {
+	c := catalogTeste(t, musicasEstatisticas...)
+	for _, q := range []struct {
+		acorde  string
+		generos []string
+		want    []string
+	}{
+		{"A#", nil, []string{"e_b", "e_c", "e_h"}},
+		{"Bb", []string{"Rock"}, []string{"e_b", "e_h"}},
+		{"Gm", nil, nil},
+	} {
+		generos := sets.NewSet()
+		for _, g := range q.generos {
+			generos.Add(g)
+		}
+		var got []string
+		for _, m := range c.MusicasComAcorde(q.acorde, generos) {
+			got = append(got, m.UniqueID)
+		}
+		if !reflect.DeepEqual(got, q.want) {
+			t.Errorf("MusicasComAcorde(%q, %v) = %v, want %v", q.acorde, q.generos, got, q.want)
+		}
+	}
+}
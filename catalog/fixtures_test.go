@@ -0,0 +1,112 @@
+package catalog
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+// catalogTeste constrói o catálogo de um dataset pequeno, com as linhas
+// (sem cabeçalho) nas colunas padrão.
+func catalogTeste(t *testing.T, linhas ...string) *Catalog {
+	t.Helper()
+	dataset := strings.Join(colunasPadrao, ",") + "\n" + strings.Join(linhas, "\n")
+	c, err := New(strings.NewReader(dataset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(c.LinhasInvalidas()) > 0 {
+		t.Fatalf("linhas inválidas no dataset de teste: %v", c.LinhasInvalidas())
+	}
+	return c
+}
+
+// Vocabulário de acordes do catálogo sintético. Os primeiros acordes são os
+// mais frequentes, como no dataset real.
+var acordesSinteticos = []string{
+	"G", "C", "D", "Am", "Em", "F", "A", "E", "Bm", "Dm",
+	"E7", "A7", "D7", "B7", "G7", "C7", "F#m", "Bb", "Cm", "Gm",
+	"C#m", "B", "Eb", "Ab", "F#", "Fm", "Bbm", "G#m", "D#m", "Am7",
+	"Em7", "Dm7", "Bm7", "C7M", "G7M", "F7M", "Dsus4", "Asus4", "C(9)", "G/B",
+	"D/F#", "C/E", "Bm7(b5)", "B°", "F#m7(b5)", "E°", "A(9)", "D(9)", "E4", "A4",
+}
+
+var generosSinteticos = []string{"Rock", "MPB", "Sertanejo", "Samba", "Gospel", "Forró"}
+
+// Palavras dos nomes de artistas e músicas, também das mais frequentes para
+// as menos frequentes.
+var palavrasSinteticas = []string{
+	"de", "o", "a", "e", "do", "da", "amor", "eu", "você", "meu",
+	"coração", "não", "vida", "te", "banda", "sem", "dia", "mais", "tempo", "noite",
+	"sonho", "saudade", "céu", "mar", "estrela", "canção", "paixão", "luz", "caminho", "lua",
+	"sol", "flor", "casa", "terra", "fé", "voz", "rio", "cidade", "sertão", "menina",
+	"jardim", "segredo", "brisa", "viola", "chuva", "lágrima", "destino", "janela", "poeira", "violeiro",
+}
+
+// Sílabas das palavras de vocabularioSintetico.
+var silabasSinteticas = []string{
+	"ba", "be", "ca", "ção", "da", "de", "do", "fa", "fe", "ga", "go", "la",
+	"le", "li", "lu", "ma", "me", "mi", "na", "ne", "no", "pa", "pe", "ra",
+	"re", "ri", "ro", "sa", "se", "so", "ta", "te", "ti", "va", "ve", "vi",
+}
+
+// vocabularioSintetico gera n palavras distintas de duas a quatro sílabas,
+// para catálogos com um vocabulário de nomes do tamanho do dataset real
+// (dezenas de milhares de termos).
+func vocabularioSintetico(n int) []string {
+	r := rand.New(rand.NewSource(3))
+	vistas := make(map[string]bool)
+	var palavras []string
+	for len(palavras) < n {
+		var b strings.Builder
+		for i := 0; i < 2+r.Intn(3); i++ {
+			b.WriteString(silabasSinteticas[r.Intn(len(silabasSinteticas))])
+		}
+		if p := b.String(); !vistas[p] {
+			vistas[p] = true
+			palavras = append(palavras, p)
+		}
+	}
+	return palavras
+}
+
+// catalogSintetico gera um catálogo com n músicas de n/10+1 artistas. As
+// cifras têm de 4 a 11 acordes e, assim como as palavras dos nomes, seguem
+// distribuições de Zipf sobre os vocabulários: poucos termos muito comuns e
+// uma cauda longa de termos raros. O nome de cada música começa por uma
+// palavra diferente do vocabulário, de forma que todas as palavras aparecem
+// no catálogo quando n não é menor que o vocabulário.
+func catalogSintetico(n int, palavras []string) *Catalog {
+	r := rand.New(rand.NewSource(1))
+	zipfAcordes := rand.NewZipf(r, 1.3, 2, uint64(len(acordesSinteticos)-1))
+	zipfPalavras := rand.NewZipf(r, 1.1, 2, uint64(len(palavras)-1))
+	nome := func(n int) string {
+		var nome []string
+		for i := 0; i < n; i++ {
+			nome = append(nome, palavras[zipfPalavras.Uint64()])
+		}
+		return strings.Join(nome, " ")
+	}
+	artistas := make([]string, n/10+1)
+	for i := range artistas {
+		artistas[i] = nome(1 + r.Intn(3))
+	}
+	var musicas []*Musica
+	for i := 0; i < n; i++ {
+		m := &Musica{
+			UniqueID:     fmt.Sprintf("artista-%d_musica-%d", i%len(artistas), i),
+			Artista:      artistas[i%len(artistas)],
+			Nome:         strings.TrimSpace(palavras[i%len(palavras)] + " " + nome(r.Intn(4))),
+			Genero:       generosSinteticos[r.Intn(len(generosSinteticos))],
+			Popularidade: r.Intn(100000),
+		}
+		var cifra []string
+		for j := 0; j < 4+r.Intn(8); j++ {
+			cifra = append(cifra, acordesSinteticos[zipfAcordes.Uint64()])
+		}
+		m.Cifra, m.Estruturas, _ = canonizaCifra(cifra)
+		musicas = append(musicas, m)
+	}
+	return novoCatalog(musicas)
+}
@@ -0,0 +1,174 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+const (
+	// Número de tons e de acordes mais comuns nas estatísticas de cada gênero.
+	NUM_TONS_GENERO    = 3
+	NUM_ACORDES_GENERO = 10
+)
+
+// aliasesGenero mapeia nomes alternativos de gêneros, já normalizados, para o
+// nome normalizado do gênero correspondente.
+var aliasesGenero = map[string]string{
+	"musica popular brasileira": "mpb",
+	"gospel":                    "gospel/religioso",
+	"religioso":                 "gospel/religioso",
+	"hip hop":                   "hip hop/rap",
+	"rap":                       "hip hop/rap",
+	"rock n roll":               "rock and roll",
+	"rock'n'roll":               "rock and roll",
+}
+
+// chaveGenero identifica o gênero independentemente de maiúsculas, acentos,
+// espaços e aliases: "Forró", "forro " e "FORRO" possuem a mesma chave.
+func chaveGenero(genero string) string {
+	chave := RemoverCombinantes(strings.ToLower(grafiaGenero(genero)))
+	if alias, ok := aliasesGenero[chave]; ok {
+		return alias
+	}
+	return chave
+}
+
+// grafiaGenero remove os espaços do início e do fim do nome do gênero e
+// substitui as sequências de espaços internas por um único espaço.
+func grafiaGenero(genero string) string {
+	return strings.Join(strings.Fields(genero), " ")
+}
+
+// normalizaGeneros agrupa os gêneros das músicas pela chave e substitui o
+// gênero de cada música pela grafia mais usada no seu grupo (empates são
+// desfeitos pela ordem alfabética).
+func (c *Catalog) normalizaGeneros(musicas []*Musica) {
+	grafias := make(map[string]map[string]int)
+	for _, m := range musicas {
+		chave := chaveGenero(m.Genero)
+		if _, ok := grafias[chave]; !ok {
+			grafias[chave] = make(map[string]int)
+		}
+		grafias[chave][grafiaGenero(m.Genero)]++
+	}
+	c.generosPorChave = make(map[string]string)
+	c.variantesGenero = make(map[string][]string)
+	for chave, contagem := range grafias {
+		var nome string
+		var variantes []string
+		for g, n := range contagem {
+			variantes = append(variantes, g)
+			if nome == "" || n > contagem[nome] || n == contagem[nome] && g < nome {
+				nome = g
+			}
+		}
+		sort.Strings(variantes)
+		c.generosPorChave[chave] = nome
+		c.variantesGenero[nome] = variantes
+	}
+	for _, m := range musicas {
+		m.Genero = c.generosPorChave[chaveGenero(m.Genero)]
+	}
+}
+
+// Genero retorna o nome do gênero como aparece no catálogo, a partir de
+// qualquer uma das suas grafias ou aliases.
+func (c *Catalog) Genero(genero string) (string, bool) {
+	nome, ok := c.generosPorChave[chaveGenero(genero)]
+	return nome, ok
+}
+
+// NormalizaGeneros retorna os nomes dos gêneros passados como aparecem no
+// catálogo. Gêneros desconhecidos são mantidos, de forma que os filtros por
+// eles continuem não retornando músicas.
+func (c *Catalog) NormalizaGeneros(generos sets.Set) sets.Set {
+	normalizados := sets.NewSet()
+	for _, g := range generos.ToSlice() {
+		if nome, ok := c.Genero(g.(string)); ok {
+			normalizados.Add(nome)
+		} else {
+			normalizados.Add(g)
+		}
+	}
+	return normalizados
+}
+
+// EstatisticaGenero descreve as músicas de um gênero do catálogo.
+type EstatisticaGenero struct {
+	Genero string `json:"genero"`
+	// Grafias do gênero encontradas no dataset, agrupadas neste gênero.
+	Variantes []string `json:"variantes"`
+	Musicas   int      `json:"musicas"`
+	Artistas  int      `json:"artistas"`
+	// Tons e acordes (na forma enarmônica) que aparecem em mais músicas.
+	Tons    []Frequencia `json:"tons_mais_comuns"`
+	Acordes []Frequencia `json:"acordes_mais_comuns"`
+	// Número médio de acordes distintos por música.
+	MediaAcordes float64 `json:"media_acordes"`
+}
+
+// indexaGeneros calcula as estatísticas de cada gênero. As músicas já devem
+// estar ordenadas, de forma que empates sejam desfeitos pela popularidade.
+func (c *Catalog) indexaGeneros() {
+	estatisticas := make(map[string]*EstatisticaGenero)
+	artistas := make(map[string]sets.Set)
+	acordes := make(map[string]*contador)
+	tons := make(map[string]*contador)
+	totalAcordes := make(map[string]int)
+	for _, m := range c.musicas {
+		e, ok := estatisticas[m.Genero]
+		if !ok {
+			e = &EstatisticaGenero{Genero: m.Genero, Variantes: c.variantesGenero[m.Genero]}
+			estatisticas[m.Genero] = e
+			artistas[m.Genero] = sets.NewSet()
+			acordes[m.Genero], tons[m.Genero] = novoContador(), novoContador()
+		}
+		e.Musicas++
+		artistas[m.Genero].Add(m.IDArtista)
+		acordes[m.Genero].contaAcordes(m)
+		totalAcordes[m.Genero] += m.AcordesEnarmonicos().Cardinality()
+		if tom, ok := m.Tonalidade(); ok {
+			tom = tom.Transpoe(0)
+			tons[m.Genero].conta(tom.String(), tom.String())
+		}
+	}
+	c.estatisticasGeneros = nil
+	for g, e := range estatisticas {
+		e.Artistas = artistas[g].Cardinality()
+		e.Tons = tons[g].maisFrequentes(NUM_TONS_GENERO)
+		e.Acordes = acordes[g].maisFrequentes(NUM_ACORDES_GENERO)
+		e.MediaAcordes = float64(totalAcordes[g]) / float64(e.Musicas)
+		c.estatisticasGeneros = append(c.estatisticasGeneros, e)
+	}
+	sort.Slice(c.estatisticasGeneros, func(i, j int) bool {
+		return c.estatisticasGeneros[i].Genero < c.estatisticasGeneros[j].Genero
+	})
+}
+
+// EstatisticasGeneros retorna as estatísticas de todos os gêneros do
+// catálogo, ordenados pelo nome.
+func (c *Catalog) EstatisticasGeneros() []*EstatisticaGenero {
+	return c.estatisticasGeneros
+}
+
+// FacetasGeneros conta as músicas de cada gênero, ordenando os gêneros pelo
+// número de músicas.
+func FacetasGeneros(musicas []*Musica) []Frequencia {
+	contagem := make(map[string]int)
+	for _, m := range musicas {
+		contagem[m.Genero]++
+	}
+	facetas := []Frequencia{}
+	for g, n := range contagem {
+		facetas = append(facetas, Frequencia{g, n})
+	}
+	sort.Slice(facetas, func(i, j int) bool {
+		if facetas[i].Musicas != facetas[j].Musicas {
+			return facetas[i].Musicas > facetas[j].Musicas
+		}
+		return facetas[i].Valor < facetas[j].Valor
+	})
+	return facetas
+}
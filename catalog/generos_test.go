@@ -0,0 +1,117 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+// Grafias e aliases de três gêneros: Forró, Gospel (ou Religioso) e MPB.
+var musicasGeneros = []string{
+	"g,a,G,A,Forró,500,G,NA,G;D;C",
+	"g,b,G,B,Forró,400,G,NA,G;C",
+	"h,c,H,C,forro  ,300,D,NA,D;A",
+	"h,d,H,D,Gospel,200,C,NA,C;F",
+	"h,e,H,E,Religioso,100,C,NA,C;G;Am",
+	"i,f,I,F,MPB,90,A,NA,A;D",
+	"i,g,I,G,Música Popular Brasileira,80,A,NA,A;E",
+	"i,h,I,H,MPB,70,Em,NA,Em;C",
+}
+
+func TestGenero(t *testing.T) {
+	c := catalogTeste(t, musicasGeneros...)
+	for _, q := range []struct {
+		genero, want string
+	}{
+		// A grafia mais usada dá nome ao gênero.
+		{"forró", "Forró"},
+		{"  FORRO ", "Forró"},
+		// Empates são desfeitos pela ordem alfabética.
+		{"religioso", "Gospel"},
+		{"gospel", "Gospel"},
+		{"Musica popular brasileira", "MPB"},
+		{"mpb", "MPB"},
+		{"rap", ""},
+	} {
+		got, ok := c.Genero(q.genero)
+		if got != q.want || ok != (q.want != "") {
+			t.Errorf("Genero(%q) = %q, %v, want %q", q.genero, got, ok, q.want)
+		}
+	}
+
+	// Gêneros desconhecidos são mantidos.
+	got := c.NormalizaGeneros(sets.NewSet("forro", "religioso", "Sertanejo"))
+	if want := sets.NewSet("Forró", "Gospel", "Sertanejo"); !got.Equal(want) {
+		t.Errorf("NormalizaGeneros = %v, want %v", got, want)
+	}
+
+	var musicas []string
+	for _, m := range c.Filtra(c.NormalizaGeneros(sets.NewSet("religioso"))) {
+		musicas = append(musicas, m.UniqueID)
+	}
+	if want := []string{"h_d", "h_e"}; !reflect.DeepEqual(musicas, want) {
+		t.Errorf("Filtra(religioso) = %v, want %v", musicas, want)
+	}
+}
+
+func TestEstatisticasGeneros(t *testing.T) {
+	c := catalogTeste(t, musicasGeneros...)
+	want := []EstatisticaGenero{
+		{
+			Genero:       "Forró",
+			Variantes:    []string{"Forró", "forro"},
+			Musicas:      3,
+			Artistas:     2,
+			Tons:         []Frequencia{{"G", 2}, {"D", 1}},
+			Acordes:      []Frequencia{{"G", 2}, {"D", 2}, {"C", 2}, {"A", 1}},
+			MediaAcordes: 7.0 / 3,
+		},
+		{
+			Genero:       "Gospel",
+			Variantes:    []string{"Gospel", "Religioso"},
+			Musicas:      2,
+			Artistas:     1,
+			Tons:         []Frequencia{{"C", 2}},
+			Acordes:      []Frequencia{{"C", 2}, {"F", 1}, {"G", 1}, {"Am", 1}},
+			MediaAcordes: 2.5,
+		},
+		{
+			Genero:       "MPB",
+			Variantes:    []string{"MPB", "Música Popular Brasileira"},
+			Musicas:      3,
+			Artistas:     1,
+			Tons:         []Frequencia{{"A", 2}, {"Em", 1}},
+			Acordes:      []Frequencia{{"A", 2}, {"D", 1}, {"E", 1}, {"Em", 1}, {"C", 1}},
+			MediaAcordes: 2,
+		},
+	}
+	var got []EstatisticaGenero
+	for _, e := range c.EstatisticasGeneros() {
+		got = append(got, *e)
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("EstatisticasGeneros() = %+v, want %+v", got, want)
+	}
+}
+
+func TestFacetasGeneros(t *testing.T) {
+	c := catalogTeste(t, musicasGeneros...)
+	for _, q := range []struct {
+		musicas []string
+		want    []Frequencia
+	}{
+		{[]string{"g_a", "g_b", "h_c", "h_d", "h_e", "i_f", "i_g", "i_h"}, []Frequencia{{"Forró", 3}, {"MPB", 3}, {"Gospel", 2}}},
+		{[]string{"h_e", "i_g", "h_c"}, []Frequencia{{"Forró", 1}, {"Gospel", 1}, {"MPB", 1}}},
+		{nil, []Frequencia{}},
+	} {
+		var musicas []*Musica
+		for _, id := range q.musicas {
+			m, _ := c.Musica(id)
+			musicas = append(musicas, m)
+		}
+		if got := FacetasGeneros(musicas); !reflect.DeepEqual(got, q.want) {
+			t.Errorf("FacetasGeneros(%v) = %v, want %v", q.musicas, got, q.want)
+		}
+	}
+}
@@ -0,0 +1,196 @@
+package catalog
+
+import (
+	"fmt"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	sets "github.com/deckarep/golang-set"
+)
+
+type Musica struct {
+	IDArtista    string   `json:"id_artista"`
+	UniqueID     string   `json:"id_unico_musica"`
+	Genero       string   `json:"genero"`
+	ID           string   `json:"id_musica"`
+	Artista      string   `json:"nome_artista"`
+	Nome         string   `json:"nome_musica"`
+	URL          string   `json:"url"`
+	Popularidade int      `json:"popularidade"`
+	Cifra        []string `json:"cifra"`
+	SeqFamosas   []string `json:"seq_famosas"`
+	Tom          string   `json:"tom"`
+
+	// Estrutura de cada acorde da cifra, na mesma ordem de Cifra. Os acordes
+	// são interpretados uma única vez, na leitura do dataset.
+	Estruturas []acorde.Acorde `json:"-"`
+
+	// Tom estimado a partir da cifra e a confiança da estimativa (entre 0 e 1).
+	TomEstimado  string  `json:"tom_estimado"`
+	ConfiancaTom float64 `json:"confianca_tom_estimado"`
+	// Verdadeiro quando o tom do dataset diverge do tom estimado com
+	// confiança de ao menos CONFIANCA_TOM_DIVERGENTE.
+	TomDivergente bool `json:"tom_divergente"`
+
+	// Trechos da cifra original que não puderam ser interpretados como
+	// acordes. Não fazem parte de Cifra nem dos índices do catálogo.
+	AcordesInvalidos []string `json:"-"`
+
+	// Forma enarmônica canônica de cada acorde da cifra.
+	enarmonicos map[string]string
+	// Grau de cada acorde da cifra em relação ao tom da música.
+	graus map[string]string
+
+	// Posição da música no catálogo (ordenado por popularidade).
+	posicao int
+	// Bitset dos termos da música e sequência da cifra em cada forma de
+	// comparação.
+	termos     [numComparacoes]bitset
+	sequencias [numComparacoes]sequencia
+}
+
+func (m *Musica) Acordes() sets.Set {
+	acordes := sets.NewSet()
+	for _, c := range m.Cifra {
+		acordes.Add(c)
+	}
+	return acordes
+}
+
+// AcordesEnarmonicos retorna o conjunto de acordes da música em sua forma
+// enarmônica canônica (A# e Bb, por exemplo, resultam no mesmo acorde).
+func (m *Musica) AcordesEnarmonicos() sets.Set {
+	acordes := sets.NewSet()
+	for _, c := range m.Cifra {
+		acordes.Add(m.ChaveEnarmonica(c))
+	}
+	return acordes
+}
+
+// ChaveEnarmonica retorna a forma enarmônica canônica de um acorde da cifra
+// da música.
+func (m *Musica) ChaveEnarmonica(a string) string {
+	if chave, ok := m.enarmonicos[a]; ok {
+		return chave
+	}
+	chave, err := acorde.ChaveEnarmonica(a)
+	if err != nil {
+		return a
+	}
+	return chave
+}
+
+// Estrutura retorna a estrutura de um acorde da cifra da música.
+func (m *Musica) Estrutura(a string) (acorde.Acorde, bool) {
+	for i, c := range m.Cifra {
+		if c == a {
+			return m.Estruturas[i], true
+		}
+	}
+	return acorde.Acorde{}, false
+}
+
+// grafia retorna a grafia usada na cifra da música para o acorde na forma
+// enarmônica canônica passada.
+func (m *Musica) grafia(chave string) string {
+	for _, c := range m.Cifra {
+		if m.ChaveEnarmonica(c) == chave {
+			return c
+		}
+	}
+	return chave
+}
+
+// indexaEnarmonicos calcula a forma enarmônica de cada acorde da cifra.
+func (m *Musica) indexaEnarmonicos() {
+	m.enarmonicos = make(map[string]string)
+	for i, c := range m.Cifra {
+		if _, ok := m.enarmonicos[c]; !ok {
+			m.enarmonicos[c] = m.Estruturas[i].Enarmonico().String()
+		}
+	}
+}
+
+// Confiança mínima da estimativa para que o tom estimado prevaleça sobre um
+// tom do dataset divergente.
+const CONFIANCA_TOM_DIVERGENTE = 0.5
+
+// Tonalidade retorna o tom da música. Caso o tom não esteja definido no
+// dataset, seja inválido ou divirja do tom estimado (veja TomDivergente),
+// retorna o tom estimado a partir da cifra.
+func (m *Musica) Tonalidade() (acorde.Tom, bool) {
+	if tom, err := acorde.ParseTom(m.Tom); err == nil && !m.TomDivergente {
+		return tom, true
+	}
+	tom, err := acorde.ParseTom(m.TomEstimado)
+	return tom, err == nil
+}
+
+// estimaTom preenche o tom estimado da música a partir da sua cifra e
+// verifica se o tom do dataset diverge da estimativa. O tom relativo (Am
+// para C) não é considerado divergente: os dois tons compartilham os
+// acordes, e a confiança da estimativa é calculada apenas em relação aos
+// demais tons.
+func (m *Musica) estimaTom() {
+	estimado, confianca, ok := acorde.EstimaTom(m.Estruturas)
+	if !ok {
+		return
+	}
+	m.TomEstimado = estimado.String()
+	m.ConfiancaTom = confianca
+	if tom, err := acorde.ParseTom(m.Tom); err == nil && confianca >= CONFIANCA_TOM_DIVERGENTE {
+		m.TomDivergente = !mesmoTom(tom, estimado) && !mesmoTom(tom.Relativo(), estimado)
+	}
+}
+
+// mesmoTom indica se os tons são iguais, independentemente da grafia da
+// tônica (A# e Bb, por exemplo).
+func mesmoTom(a, b acorde.Tom) bool {
+	return a.Altura() == b.Altura() && a.Menor == b.Menor
+}
+
+// Graus retorna o conjunto de graus (funções dos acordes em relação ao tom
+// da música, como I, V7 e vi). Retorna falso caso o tom da música não seja
+// conhecido.
+func (m *Musica) Graus() (sets.Set, bool) {
+	if m.graus == nil {
+		return nil, false
+	}
+	graus := sets.NewSet()
+	for _, g := range m.graus {
+		graus.Add(g)
+	}
+	return graus, true
+}
+
+// Grau retorna a função de um acorde da cifra em relação ao tom da música.
+func (m *Musica) Grau(a string) string {
+	return m.graus[a]
+}
+
+// indexaGraus calcula o grau de cada acorde da cifra em relação ao tom da
+// música.
+func (m *Musica) indexaGraus() {
+	tom, ok := m.Tonalidade()
+	if !ok {
+		return
+	}
+	m.graus = make(map[string]string)
+	for i, c := range m.Cifra {
+		m.graus[c] = m.Estruturas[i].Grau(tom.Altura())
+	}
+}
+
+func UniqueID(artista, id string) string {
+	return fmt.Sprintf("%s_%s", artista, id)
+}
+
+func URL(artista, id string) string {
+	return fmt.Sprintf("http://www.cifraclub.com.br/%s/%s", artista, id)
+}
+
+// PorPopularidade implementa sort.Interface for []*Musica baseado no campo Popularidade
+type PorPopularidade []*Musica
+
+func (p PorPopularidade) Len() int           { return len(p) }
+func (p PorPopularidade) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
+func (p PorPopularidade) Less(i, j int) bool { return p[i].Popularidade > p[j].Popularidade }
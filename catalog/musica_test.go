@@ -0,0 +1,36 @@
+package catalog
+
+import "testing"
+
+func TestTonalidade(t *testing.T) {
+	c := catalogTeste(t,
+		"m,igual,M,Igual,Rock,600,C,NA,C;F;G7;C",
+		"m,divergente,M,Divergente,Rock,500,D,NA,C;F;G7;C",
+		"m,relativo,M,Relativo,Rock,400,Am,NA,C;F;G7;C",
+		"m,incerto,M,Incerto,Rock,300,D,NA,C;G;Am;F",
+		"m,sem-tom,M,Sem Tom,Rock,200,NA,NA,C;F;G7;C",
+		"m,grafia,M,Grafia,Rock,100,A#,NA,Bb;Eb;F7;Bb",
+	)
+	for _, q := range []struct {
+		id         string
+		want       string
+		divergente bool
+	}{
+		{"m_igual", "C", false},
+		// A estimativa confiante prevalece sobre o tom do dataset.
+		{"m_divergente", "C", true},
+		// Tons relativos compartilham os acordes.
+		{"m_relativo", "Am", false},
+		// Sem confiança suficiente, o tom do dataset é mantido.
+		{"m_incerto", "D", false},
+		{"m_sem-tom", "C", false},
+		{"m_grafia", "A#", false},
+	} {
+		m, _ := c.Musica(q.id)
+		tom, ok := m.Tonalidade()
+		if !ok || tom.String() != q.want || m.TomDivergente != q.divergente {
+			t.Errorf("%s: Tonalidade() = %v, %v, TomDivergente = %v, want %s, %v (estimado %s, confiança %.2f)",
+				q.id, tom, ok, m.TomDivergente, q.want, q.divergente, m.TomEstimado, m.ConfiancaTom)
+		}
+	}
+}
@@ -0,0 +1,203 @@
+package catalog
+
+import (
+	sets "github.com/deckarep/golang-set"
+)
+
+// Número máximo de acordes admitidos entre dois acordes consecutivos de uma
+// progressão.
+const MAX_LACUNAS = 3
+
+// sequencia é a cifra de uma música na forma de uma comparação, com os termos
+// numerados pelo dicionário e sem repetições consecutivas (C C G é a mesma
+// progressão que C G).
+type sequencia struct {
+	termos []int32
+	// Posição na cifra de cada termo da sequência.
+	posicoes []int32
+}
+
+// bigrama identifica um par de termos consecutivos.
+func bigrama(a, b int32) uint64 {
+	return uint64(uint32(a))<<32 | uint64(uint32(b))
+}
+
+// indexaProgressoes constrói a sequência de cada música e, em cada
+// dicionário, o índice de músicas por bigrama (par de termos consecutivos).
+// Como toda ocorrência contígua de uma progressão contém todos os seus
+// bigramas, a busca só precisa verificar as músicas que possuem o bigrama
+// mais raro da progressão.
+func (c *Catalog) indexaProgressoes() {
+	for comparacao, d := range c.dicionarios {
+		d.bigramas = make(map[uint64][]int32)
+		for p, m := range c.musicas {
+			var s sequencia
+			for i, a := range m.Cifra {
+				t := m.Termo(comparacao, a)
+				if t == "" {
+					continue
+				}
+				n := int32(d.numeros[t])
+				if len(s.termos) > 0 && s.termos[len(s.termos)-1] == n {
+					continue
+				}
+				s.termos = append(s.termos, n)
+				s.posicoes = append(s.posicoes, int32(i))
+			}
+			m.sequencias[comparacao] = s
+			for i := 1; i < len(s.termos); i++ {
+				k := bigrama(s.termos[i-1], s.termos[i])
+				if l := d.bigramas[k]; len(l) == 0 || l[len(l)-1] != int32(p) {
+					d.bigramas[k] = append(l, int32(p))
+				}
+			}
+		}
+	}
+}
+
+// ConsultaProgressao descreve uma busca por músicas que tocam uma sequência
+// de acordes.
+type ConsultaProgressao struct {
+	// Termos da progressão, em ordem e na forma da comparação (veja
+	// Musica.Termo).
+	Termos     []string
+	Comparacao int
+	// Número máximo de acordes entre dois acordes consecutivos da progressão
+	// (limitado a MAX_LACUNAS).
+	Lacunas int
+	// Caso verdadeiro, qualquer rotação da progressão é aceita (Am F C G
+	// também encontra F C G Am).
+	Ciclica bool
+	// Caso não seja vazio, apenas músicas de algum dos gêneros são retornadas.
+	Generos sets.Set
+}
+
+// Trecho é uma ocorrência da progressão na cifra de uma música.
+type Trecho struct {
+	Posicoes []int    `json:"posicoes"` // posição na cifra de cada acorde.
+	Acordes  []string `json:"acordes"`  // acordes como aparecem na cifra.
+}
+
+// Ocorrencias reúne os trechos de uma música que tocam a progressão.
+type Ocorrencias struct {
+	Musica  *Musica
+	Trechos []Trecho
+}
+
+// Progressoes retorna as músicas que tocam a progressão, ordenadas por
+// popularidade. Os trechos de uma música não se sobrepõem.
+func (c *Catalog) Progressoes(q ConsultaProgressao) []Ocorrencias {
+	lacunas := q.Lacunas
+	if lacunas < 0 {
+		lacunas = 0
+	}
+	if lacunas > MAX_LACUNAS {
+		lacunas = MAX_LACUNAS
+	}
+
+	d := c.dicionarios[q.Comparacao]
+	var progressao []int32
+	for _, t := range q.Termos {
+		n, ok := d.numeros[t]
+		if !ok {
+			// Nenhuma música possui o termo.
+			return nil
+		}
+		if len(progressao) > 0 && progressao[len(progressao)-1] == int32(n) {
+			continue
+		}
+		progressao = append(progressao, int32(n))
+	}
+	if len(progressao) == 0 {
+		return nil
+	}
+	rotacoes := [][]int32{progressao}
+	if q.Ciclica {
+		for i := 1; i < len(progressao); i++ {
+			rotacao := append(append([]int32{}, progressao[i:]...), progressao[:i]...)
+			rotacoes = append(rotacoes, rotacao)
+		}
+	}
+
+	candidatas := novoBitset(len(c.musicas))
+	if lacunas == 0 && len(progressao) > 1 {
+		for _, rotacao := range rotacoes {
+			var raras []int32
+			for i := 1; i < len(rotacao); i++ {
+				l := d.bigramas[bigrama(rotacao[i-1], rotacao[i])]
+				if i == 1 || len(l) < len(raras) {
+					raras = l
+				}
+			}
+			for _, p := range raras {
+				candidatas.adiciona(int(p))
+			}
+		}
+	} else {
+		// Com lacunas, os termos não precisam ser consecutivos: as candidatas
+		// são as músicas que possuem todos os termos da progressão.
+		var termos bitset
+		raras := d.musicas[progressao[0]]
+		for _, n := range progressao {
+			termos.adiciona(int(n))
+			if len(d.musicas[n]) < len(raras) {
+				raras = d.musicas[n]
+			}
+		}
+		numTermos := termos.cardinalidade()
+		for _, p := range raras {
+			if c.musicas[p].termos[q.Comparacao].intersecao(termos) == numTermos {
+				candidatas.adiciona(int(p))
+			}
+		}
+	}
+	if q.Generos != nil && q.Generos.Cardinality() > 0 {
+		candidatas.intersecta(c.bitsGeneros(q.Generos))
+	}
+
+	var ocorrencias []Ocorrencias
+	candidatas.itera(func(p int) {
+		m := c.musicas[p]
+		s := m.sequencias[q.Comparacao]
+		o := Ocorrencias{Musica: m}
+		for i := 0; i < len(s.termos); i++ {
+			for _, rotacao := range rotacoes {
+				indices := casa(s.termos, i, rotacao, lacunas, nil)
+				if indices == nil {
+					continue
+				}
+				var t Trecho
+				for _, j := range indices {
+					t.Posicoes = append(t.Posicoes, int(s.posicoes[j]))
+					t.Acordes = append(t.Acordes, m.Cifra[s.posicoes[j]])
+				}
+				o.Trechos = append(o.Trechos, t)
+				i = indices[len(indices)-1]
+				break
+			}
+		}
+		if len(o.Trechos) > 0 {
+			ocorrencias = append(ocorrencias, o)
+		}
+	})
+	return ocorrencias
+}
+
+// casa verifica se a progressão ocorre na sequência a partir do índice i,
+// admitindo até lacunas termos entre dois termos consecutivos da progressão.
+// Retorna os índices da sequência que formam a progressão ou nil.
+func casa(sequencia []int32, i int, progressao []int32, lacunas int, indices []int) []int {
+	if sequencia[i] != progressao[0] {
+		return nil
+	}
+	indices = append(indices, i)
+	if len(progressao) == 1 {
+		return indices
+	}
+	for j := i + 1; j < len(sequencia) && j <= i+1+lacunas; j++ {
+		if r := casa(sequencia, j, progressao[1:], lacunas, indices); r != nil {
+			return r
+		}
+	}
+	return nil
+}
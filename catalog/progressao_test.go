@@ -0,0 +1,77 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestProgressoes(t *testing.T) {
+	c := catalogTeste(t,
+		"p,um,P,Um,Rock,600,C,NA,C;G;Am;F",
+		"p,dois,P,Dois,Rock,500,C,NA,C;C;G;Am;F",
+		"p,tres,P,Tres,Rock,400,C,NA,C;Em;G;D;Am;F",
+		"p,quatro,P,Quatro,Rock,300,C,NA,F;C;G;Am",
+		"p,cinco,P,Cinco,Rock,200,C,NA,G;C",
+		"p,seis,P,Seis,Rock,100,C,NA,C;G;C;G",
+	)
+	for _, q := range []struct {
+		termos  []string
+		lacunas int
+		ciclica bool
+		// Posições dos trechos encontrados em cada música.
+		want map[string][][]int
+	}{
+		// A ordem dos acordes importa: G C não é C G.
+		{[]string{"C", "G"}, 0, false, map[string][][]int{
+			"p_um":     {{0, 1}},
+			"p_dois":   {{0, 2}},
+			"p_quatro": {{1, 2}},
+			"p_seis":   {{0, 1}, {2, 3}},
+		}},
+		{[]string{"G", "C"}, 0, false, map[string][][]int{
+			"p_cinco": {{0, 1}},
+			"p_seis":  {{1, 2}},
+		}},
+		// Repetições consecutivas não contam como acordes diferentes.
+		{[]string{"C", "C", "G"}, 0, false, map[string][][]int{
+			"p_um":     {{0, 1}},
+			"p_dois":   {{0, 2}},
+			"p_quatro": {{1, 2}},
+			"p_seis":   {{0, 1}, {2, 3}},
+		}},
+		// Com lacunas, acordes podem aparecer entre os da progressão.
+		{[]string{"C", "G"}, 1, false, map[string][][]int{
+			"p_um":     {{0, 1}},
+			"p_dois":   {{0, 2}},
+			"p_tres":   {{0, 2}},
+			"p_quatro": {{1, 2}},
+			"p_seis":   {{0, 1}, {2, 3}},
+		}},
+		{[]string{"C", "G", "Am", "F"}, 0, false, map[string][][]int{
+			"p_um":   {{0, 1, 2, 3}},
+			"p_dois": {{0, 2, 3, 4}},
+		}},
+		{[]string{"C", "G", "Am", "F"}, 2, false, map[string][][]int{
+			"p_um":   {{0, 1, 2, 3}},
+			"p_dois": {{0, 2, 3, 4}},
+			"p_tres": {{0, 2, 4, 5}},
+		}},
+		// Cíclica, F C G Am é uma rotação de C G Am F.
+		{[]string{"C", "G", "Am", "F"}, 0, true, map[string][][]int{
+			"p_um":     {{0, 1, 2, 3}},
+			"p_dois":   {{0, 2, 3, 4}},
+			"p_quatro": {{0, 1, 2, 3}},
+		}},
+		{[]string{"C", "H"}, 0, false, map[string][][]int{}},
+	} {
+		got := make(map[string][][]int)
+		for _, o := range c.Progressoes(ConsultaProgressao{Termos: q.termos, Comparacao: COMPARA_GRAFIA, Lacunas: q.lacunas, Ciclica: q.ciclica}) {
+			for _, trecho := range o.Trechos {
+				got[o.Musica.UniqueID] = append(got[o.Musica.UniqueID], trecho.Posicoes)
+			}
+		}
+		if !reflect.DeepEqual(got, q.want) {
+			t.Errorf("Progressoes(%v, lacunas %d, cíclica %v) = %v, want %v", q.termos, q.lacunas, q.ciclica, got, q.want)
+		}
+	}
+}
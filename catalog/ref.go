@@ -0,0 +1,27 @@
+package catalog
+
+import "sync/atomic"
+
+// Ref guarda o catálogo em uso pelo servidor. O catálogo pode ser
+// substituído atomicamente (por exemplo, ao recarregar o dataset) enquanto
+// requisições em andamento continuam usando a versão que obtiveram com Get.
+type Ref struct {
+	v atomic.Value
+}
+
+// NewRef retorna uma referência para o catálogo c.
+func NewRef(c *Catalog) *Ref {
+	r := &Ref{}
+	r.Set(c)
+	return r
+}
+
+// Get retorna o catálogo atual.
+func (r *Ref) Get() *Catalog {
+	return r.v.Load().(*Catalog)
+}
+
+// Set substitui o catálogo atual por c.
+func (r *Ref) Set(c *Catalog) {
+	r.v.Store(c)
+}
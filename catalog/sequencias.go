@@ -0,0 +1,337 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	sets "github.com/deckarep/golang-set"
+)
+
+const (
+	// Tamanhos (em acordes) das sequências mineradas.
+	MIN_TAM_SEQUENCIA = 3
+	MAX_TAM_SEQUENCIA = 4
+	// Número máximo de sequências famosas e número mínimo de músicas que
+	// devem tocar cada uma delas.
+	NUM_SEQUENCIAS        = 50
+	MIN_MUSICAS_SEQUENCIA = 2
+	// Uma sequência contida em uma sequência maior é descartada quando a
+	// maior é tocada por ao menos essa fração das suas músicas.
+	SUBSUNCAO_SEQUENCIA = 0.8
+)
+
+// SequenciasDataset contém os acordes das sequências famosas identificadas
+// na coluna SEQ_FAMOSA do dataset, calculadas fora do servidor.
+var SequenciasDataset = map[string][]string{
+	"0": {"Bm", "G", "D", "A"},
+	"1": {"C", "G", "Am", "F"},
+	"2": {"Em", "G"},
+	"3": {"C", "A7", "Dm", "G7"},
+	"4": {"Gm", "F"},
+	"5": {"C", "C7", "F", "Fm"},
+}
+
+// Sequencia é uma progressão de acordes frequente no catálogo, independente
+// do tom em que é tocada.
+type Sequencia struct {
+	// Graus dos acordes em relação ao tom das músicas, separados por hífen
+	// (por exemplo, I-V-vi-IV, e vi-IV-I-V para Am F C G em C). Depende
+	// apenas da progressão, de forma que se mantém entre versões do dataset.
+	ID       string   `json:"id"`
+	Graus    []string `json:"graus"`
+	Acordes  []string `json:"acordes"`  // acordes na música mais popular que toca a sequência.
+	Musicas  int      `json:"musicas"`  // número de músicas que tocam a sequência.
+	Exemplos []string `json:"exemplos"` // ids das músicas mais populares que tocam a sequência.
+}
+
+// formas descreve os acordes do dicionário enarmônico independentemente da
+// raiz, para a comparação de sequências em qualquer tom.
+type formas struct {
+	raizes []int
+	// Identificador do acorde em relação à própria raiz (I7, i, I/III). -1
+	// caso o acorde não seja válido.
+	ids []int
+}
+
+func (c *Catalog) formas() formas {
+	d := c.dicionarios[COMPARA_ENARMONICO]
+	f := formas{raizes: make([]int, len(d.idf)), ids: make([]int, len(d.idf))}
+	ids := make(map[string]int)
+	for t, n := range d.numeros {
+		a, err := acorde.Parse(t)
+		if err != nil {
+			f.ids[n] = -1
+			continue
+		}
+		f.raizes[n] = a.Altura()
+		forma := a.Grau(a.Altura())
+		id, ok := ids[forma]
+		if !ok {
+			id = len(ids)
+			ids[forma] = id
+		}
+		f.ids[n] = id
+	}
+	return f
+}
+
+// chave identifica a sequência de acordes, tocada em uma música cuja tônica
+// é a classe de altura passada, independentemente do tom. Cada acorde ocupa
+// 16 bits: o intervalo em relação à tônica e o identificador da forma.
+// Retorna falso caso algum acorde se repita ou não possa ser representado.
+func (f formas) chave(termos []int32, tonica int) (uint64, bool) {
+	var k uint64
+	for i, n := range termos {
+		for _, anterior := range termos[:i] {
+			if anterior == n {
+				return 0, false
+			}
+		}
+		id := f.ids[n]
+		if id < 0 || id >= 1<<12-1 {
+			return 0, false
+		}
+		intervalo := ((f.raizes[n]-tonica)%12 + 12) % 12
+		k = k<<16 | uint64(intervalo)<<12 | uint64(id+1)
+	}
+	return k, true
+}
+
+// mineraSequencias encontra as sequências de MIN_TAM_SEQUENCIA a
+// MAX_TAM_SEQUENCIA acordes distintos tocadas pelo maior número de músicas,
+// em qualquer tom. Os acordes são comparados pela função no tom de cada
+// música, de forma que músicas sem tom conhecido são ignoradas.
+func (c *Catalog) mineraSequencias() {
+	type contagem struct {
+		chave    uint64
+		tam      int
+		musicas  int
+		ultima   int32 // última música contada.
+		exemplos []int32
+		// Posição da sequência na música mais popular e tônica da música.
+		inicio int
+		tonica int
+	}
+	f := c.formas()
+	contagens := make(map[uint64]*contagem)
+	for p, m := range c.musicas {
+		tom, ok := m.Tonalidade()
+		if !ok {
+			continue
+		}
+		termos := m.sequencias[COMPARA_ENARMONICO].termos
+		for tam := MIN_TAM_SEQUENCIA; tam <= MAX_TAM_SEQUENCIA; tam++ {
+			for i := 0; i+tam <= len(termos); i++ {
+				k, ok := f.chave(termos[i:i+tam], tom.Altura())
+				if !ok {
+					continue
+				}
+				ct, ok := contagens[k]
+				if !ok {
+					ct = &contagem{chave: k, tam: tam, ultima: -1, inicio: i, tonica: tom.Altura()}
+					contagens[k] = ct
+				}
+				if ct.ultima == int32(p) {
+					continue
+				}
+				ct.musicas++
+				ct.ultima = int32(p)
+				// As músicas são percorridas por popularidade.
+				if len(ct.exemplos) < NUM_EXEMPLOS {
+					ct.exemplos = append(ct.exemplos, int32(p))
+				}
+			}
+		}
+	}
+
+	var frequentes []*contagem
+	for _, ct := range contagens {
+		if ct.musicas >= MIN_MUSICAS_SEQUENCIA {
+			frequentes = append(frequentes, ct)
+		}
+	}
+	// Sequências maiores são consideradas antes das sequências que contêm.
+	sort.Slice(frequentes, func(i, j int) bool {
+		if frequentes[i].tam != frequentes[j].tam {
+			return frequentes[i].tam > frequentes[j].tam
+		}
+		if frequentes[i].musicas != frequentes[j].musicas {
+			return frequentes[i].musicas > frequentes[j].musicas
+		}
+		return frequentes[i].chave < frequentes[j].chave
+	})
+	// Número de músicas da maior sequência que contém cada sequência.
+	contidas := make(map[uint64]int)
+	var selecionadas []*contagem
+	for _, ct := range frequentes {
+		if float64(contidas[ct.chave]) >= SUBSUNCAO_SEQUENCIA*float64(ct.musicas) {
+			continue
+		}
+		selecionadas = append(selecionadas, ct)
+		termos := c.musicas[ct.exemplos[0]].sequencias[COMPARA_ENARMONICO].termos[ct.inicio : ct.inicio+ct.tam]
+		for tam := MIN_TAM_SEQUENCIA; tam < ct.tam; tam++ {
+			for i := 0; i+tam <= len(termos); i++ {
+				if k, ok := f.chave(termos[i:i+tam], ct.tonica); ok && contidas[k] < ct.musicas {
+					contidas[k] = ct.musicas
+				}
+			}
+		}
+	}
+	sort.Slice(selecionadas, func(i, j int) bool {
+		if selecionadas[i].musicas != selecionadas[j].musicas {
+			return selecionadas[i].musicas > selecionadas[j].musicas
+		}
+		if selecionadas[i].tam != selecionadas[j].tam {
+			return selecionadas[i].tam > selecionadas[j].tam
+		}
+		return selecionadas[i].chave < selecionadas[j].chave
+	})
+	if len(selecionadas) > NUM_SEQUENCIAS {
+		selecionadas = selecionadas[:NUM_SEQUENCIAS]
+	}
+
+	c.formasSequencias = f
+	c.sequencias = nil
+	c.sequenciasDict = make(map[string]*Sequencia)
+	c.sequenciasPorChave = make(map[uint64]*Sequencia)
+	for _, ct := range selecionadas {
+		m := c.musicas[ct.exemplos[0]]
+		s := m.sequencias[COMPARA_ENARMONICO]
+		seq := &Sequencia{Musicas: ct.musicas}
+		for i := ct.inicio; i < ct.inicio+ct.tam; i++ {
+			seq.Acordes = append(seq.Acordes, m.Cifra[s.posicoes[i]])
+			seq.Graus = append(seq.Graus, m.Estruturas[s.posicoes[i]].Grau(ct.tonica))
+		}
+		seq.ID = strings.Join(seq.Graus, "-")
+		for _, p := range ct.exemplos {
+			seq.Exemplos = append(seq.Exemplos, c.musicas[p].UniqueID)
+		}
+		c.sequencias = append(c.sequencias, seq)
+		c.sequenciasDict[seq.ID] = seq
+		c.sequenciasPorChave[ct.chave] = seq
+	}
+}
+
+// Sequencias retorna as sequências famosas, ordenadas pelo número de músicas
+// que as tocam.
+func (c *Catalog) Sequencias() []*Sequencia {
+	return c.sequencias
+}
+
+// Sequencia retorna a sequência famosa identificada pelo id.
+func (c *Catalog) Sequencia(id string) (*Sequencia, bool) {
+	s, ok := c.sequenciasDict[id]
+	return s, ok
+}
+
+// SequenciaTocada é a primeira ocorrência de uma sequência famosa em uma
+// música.
+type SequenciaTocada struct {
+	Sequencia *Sequencia
+	Trecho    Trecho
+}
+
+// SequenciasTocadas retorna as sequências famosas tocadas pela música, na
+// ordem em que aparecem na cifra.
+func (c *Catalog) SequenciasTocadas(m *Musica) []SequenciaTocada {
+	var tocadas []SequenciaTocada
+	tom, ok := m.Tonalidade()
+	if !ok {
+		return tocadas
+	}
+	vistas := make(map[*Sequencia]bool)
+	s := m.sequencias[COMPARA_ENARMONICO]
+	for i := range s.termos {
+		for tam := MIN_TAM_SEQUENCIA; tam <= MAX_TAM_SEQUENCIA && i+tam <= len(s.termos); tam++ {
+			k, ok := c.formasSequencias.chave(s.termos[i:i+tam], tom.Altura())
+			if !ok {
+				continue
+			}
+			seq, ok := c.sequenciasPorChave[k]
+			if !ok || vistas[seq] {
+				continue
+			}
+			vistas[seq] = true
+			var t Trecho
+			for _, p := range s.posicoes[i : i+tam] {
+				t.Posicoes = append(t.Posicoes, int(p))
+				t.Acordes = append(t.Acordes, m.Cifra[p])
+			}
+			tocadas = append(tocadas, SequenciaTocada{seq, t})
+		}
+	}
+	return tocadas
+}
+
+// PorSequencia retorna as músicas que tocam a sequência de acordes em
+// qualquer tom, de forma contígua, ordenadas por popularidade. Apenas
+// músicas dos gêneros passados são consideradas (todas, caso nenhum gênero
+// seja passado).
+func (c *Catalog) PorSequencia(acordes []string, generos sets.Set) ([]*Musica, error) {
+	var estruturas []acorde.Acorde
+	for _, a := range acordes {
+		estrutura, err := acorde.Parse(a)
+		if err != nil {
+			return nil, err
+		}
+		estruturas = append(estruturas, estrutura)
+	}
+	encontradas := novoBitset(len(c.musicas))
+	for semitons := 0; semitons < 12; semitons++ {
+		q := ConsultaProgressao{Comparacao: COMPARA_ENARMONICO, Generos: generos}
+		for _, e := range estruturas {
+			q.Termos = append(q.Termos, e.Transpoe(semitons, false).Enarmonico().String())
+		}
+		for _, o := range c.Progressoes(q) {
+			encontradas.adiciona(o.Musica.posicao)
+		}
+	}
+	var musicas []*Musica
+	encontradas.itera(func(p int) {
+		musicas = append(musicas, c.musicas[p])
+	})
+	return musicas, nil
+}
+
+// SequenciaDataset retorna o id da sequência do dataset (veja
+// SequenciasDataset) identificada pela chave. A chave pode ser o próprio id
+// ou os acordes da sequência, concatenados ou separados por vírgula (BmGDA ou
+// Bm,G,D,A).
+func SequenciaDataset(chave string) (string, bool) {
+	if _, ok := SequenciasDataset[chave]; ok {
+		return chave, true
+	}
+	chave = strings.Replace(chave, ",", "", -1)
+	for id, acordes := range SequenciasDataset {
+		if strings.Join(acordes, "") == chave {
+			return id, true
+		}
+	}
+	return "", false
+}
+
+// PorSequenciaDataset retorna as músicas marcadas na coluna SEQ_FAMOSA com a
+// sequência do dataset de id passado, ordenadas por popularidade. Apenas
+// músicas dos gêneros passados são consideradas (todas, caso nenhum gênero
+// seja passado).
+func (c *Catalog) PorSequenciaDataset(id string, generos sets.Set) []*Musica {
+	var candidatas bitset
+	filtra := generos != nil && generos.Cardinality() > 0
+	if filtra {
+		candidatas = c.bitsGeneros(generos)
+	}
+	var musicas []*Musica
+	for _, m := range c.musicas {
+		if filtra && !candidatas.contem(m.posicao) {
+			continue
+		}
+		for _, seq := range m.SeqFamosas {
+			if seq == id {
+				musicas = append(musicas, m)
+				break
+			}
+		}
+	}
+	return musicas
+}
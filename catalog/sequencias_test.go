@@ -0,0 +1,103 @@
+package catalog
+
+import (
+	"reflect"
+	"testing"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+// Músicas que tocam I-V-vi-IV e vi-IV-I-V em tons diferentes.
+var musicasSequencias = []string{
+	"s,um,S,Um,Rock,500,C,NA,C;G;Am;F",
+	"s,dois,S,Dois,Rock,400,D,NA,D;A;Bm;G",
+	"s,tres,S,Tres,Rock,300,C,NA,Am;F;C;G",
+	"s,quatro,S,Quatro,Rock,200,E,NA,E;B;C#m;A",
+	"s,cinco,S,Cinco,Rock,100,G,NA,Em;C;G;D",
+}
+
+// As mesmas músicas, em outros tons.
+var musicasSequenciasTranspostas = []string{
+	"s,um,S,Um,Rock,500,Eb,NA,Eb;Bb;Cm;Ab",
+	"s,dois,S,Dois,Rock,400,F,NA,F;C;Dm;Bb",
+	"s,tres,S,Tres,Rock,300,Eb,NA,Cm;Ab;Eb;Bb",
+	"s,quatro,S,Quatro,Rock,200,G,NA,G;D;Em;C",
+	"s,cinco,S,Cinco,Rock,100,Bb,NA,Gm;Eb;Bb;F",
+}
+
+func TestMineraSequencias(t *testing.T) {
+	type sequencia struct {
+		id       string
+		musicas  int
+		exemplos []string
+	}
+	want := []sequencia{
+		// As sequências de três acordes contidas nelas são descartadas.
+		{"I-V-vi-IV", 3, []string{"s_um", "s_dois", "s_quatro"}},
+		{"vi-IV-I-V", 2, []string{"s_tres", "s_cinco"}},
+	}
+	for _, musicas := range [][]string{musicasSequencias, musicasSequenciasTranspostas} {
+		c := catalogTeste(t, musicas...)
+		var got []sequencia
+		for _, s := range c.Sequencias() {
+			got = append(got, sequencia{s.ID, s.Musicas, s.Exemplos})
+			if seq, ok := c.Sequencia(s.ID); !ok || seq != s {
+				t.Errorf("Sequencia(%q) = %v, %v", s.ID, seq, ok)
+			}
+		}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("Sequencias() = %v, want %v", got, want)
+		}
+	}
+
+	// Os acordes são os da música mais popular que toca a sequência.
+	c := catalogTeste(t, musicasSequencias...)
+	if s, _ := c.Sequencia("I-V-vi-IV"); s == nil || !reflect.DeepEqual(s.Acordes, []string{"C", "G", "Am", "F"}) {
+		t.Errorf("Sequencia(I-V-vi-IV) = %+v, want acordes C G Am F", s)
+	}
+}
+
+func TestPorSequencia(t *testing.T) {
+	c := catalogTeste(t, musicasSequencias...)
+	for _, q := range []struct {
+		acordes []string
+		generos []string
+		want    []string
+	}{
+		{[]string{"A", "E", "F#m", "D"}, nil, []string{"s_um", "s_dois", "s_quatro"}},
+		{[]string{"Am", "F", "C", "G"}, nil, []string{"s_tres", "s_cinco"}},
+		{[]string{"Bbm", "Gb", "Db", "Ab"}, nil, []string{"s_tres", "s_cinco"}},
+		{[]string{"C", "G"}, nil, []string{"s_um", "s_dois", "s_tres", "s_quatro", "s_cinco"}},
+		{[]string{"C", "G"}, []string{"MPB"}, nil},
+	} {
+		generos := sets.NewSet()
+		for _, g := range q.generos {
+			generos.Add(g)
+		}
+		musicas, err := c.PorSequencia(q.acordes, generos)
+		if err != nil {
+			t.Errorf("PorSequencia(%v): %v", q.acordes, err)
+			continue
+		}
+		var got []string
+		for _, m := range musicas {
+			got = append(got, m.UniqueID)
+		}
+		if !reflect.DeepEqual(got, q.want) {
+			t.Errorf("PorSequencia(%v, %v) = %v, want %v", q.acordes, q.generos, got, q.want)
+		}
+	}
+	if _, err := c.PorSequencia([]string{"C", "X"}, sets.NewSet()); err == nil {
+		t.Error("PorSequencia(C X): want erro")
+	}
+}
+
+func TestSequenciaDataset(t *testing.T) {
+	for chave, want := range map[string]string{
+		"0": "0", "BmGDA": "0", "Bm,G,D,A": "0", "CC7FFm": "5", "I-V-vi-IV": "", "C,G": "",
+	} {
+		if got, ok := SequenciaDataset(chave); got != want || ok != (want != "") {
+			t.Errorf("SequenciaDataset(%q) = %q, %v, want %q", chave, got, ok, want)
+		}
+	}
+}
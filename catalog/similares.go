@@ -0,0 +1,240 @@
+package catalog
+
+import (
+	"math"
+	"sort"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	sets "github.com/deckarep/golang-set"
+)
+
+// Formas de comparar os acordes na busca por músicas similares.
+const (
+	// Grafias enarmônicas (A# e Bb, por exemplo) são o mesmo acorde.
+	COMPARA_ENARMONICO = iota
+	// Os acordes só são iguais quando possuem a mesma grafia.
+	COMPARA_GRAFIA
+	// São comparadas as funções dos acordes em relação ao tom de cada música
+	// (I, V7, vi).
+	COMPARA_GRAU
+	numComparacoes
+)
+
+// dicionario numera os termos (acordes ou graus) de uma forma de comparação,
+// de forma que os termos de cada música sejam representados por um bitset.
+// Os termos mais frequentes recebem os menores números: como quase todas as
+// músicas usam acordes comuns, seus bitsets ocupam poucas palavras.
+type dicionario struct {
+	numeros map[string]int
+	// Posições (em Catalog.musicas) das músicas que possuem cada termo.
+	musicas [][]int32
+	// Peso de cada termo, log(1 + N/(1+df)), e de um termo que não aparece
+	// no catálogo.
+	idf        []float64
+	idfAusente float64
+	// Posições das músicas que possuem cada par de termos consecutivos.
+	bigramas map[uint64][]int32
+}
+
+// Termo retorna a forma de um acorde da cifra da música usada na comparação
+// passada. Retorna "" caso o grau seja pedido e o tom da música não seja
+// conhecido.
+func (m *Musica) Termo(comparacao int, a string) string {
+	switch comparacao {
+	case COMPARA_ENARMONICO:
+		return m.ChaveEnarmonica(a)
+	case COMPARA_GRAU:
+		return m.Grau(a)
+	}
+	return a
+}
+
+// indexaBits constrói os dicionários de termos, os bitsets de cada música e
+// os bitsets de músicas de cada gênero. As músicas já devem estar ordenadas.
+func (c *Catalog) indexaBits() {
+	for p, m := range c.musicas {
+		m.posicao = p
+	}
+	for comparacao := range c.dicionarios {
+		df := make(map[string]int)
+		termos := make([][]string, len(c.musicas))
+		for p, m := range c.musicas {
+			vistos := make(map[string]bool)
+			for _, a := range m.Cifra {
+				t := m.Termo(comparacao, a)
+				if t == "" || vistos[t] {
+					continue
+				}
+				vistos[t] = true
+				termos[p] = append(termos[p], t)
+				df[t]++
+			}
+		}
+		ordem := make([]string, 0, len(df))
+		for t := range df {
+			ordem = append(ordem, t)
+		}
+		sort.Slice(ordem, func(i, j int) bool {
+			if df[ordem[i]] != df[ordem[j]] {
+				return df[ordem[i]] > df[ordem[j]]
+			}
+			return ordem[i] < ordem[j]
+		})
+		d := &dicionario{
+			numeros:    make(map[string]int, len(ordem)),
+			musicas:    make([][]int32, len(ordem)),
+			idf:        make([]float64, len(ordem)),
+			idfAusente: math.Log(1 + float64(len(c.musicas))),
+		}
+		for n, t := range ordem {
+			d.numeros[t] = n
+			d.idf[n] = math.Log(1 + float64(len(c.musicas))/float64(1+df[t]))
+		}
+		for p, m := range c.musicas {
+			var b bitset
+			for _, t := range termos[p] {
+				n := d.numeros[t]
+				b.adiciona(n)
+				d.musicas[n] = append(d.musicas[n], int32(p))
+			}
+			m.termos[comparacao] = b
+		}
+		c.dicionarios[comparacao] = d
+	}
+
+	c.generosBits = make(map[string]bitset)
+	for p, m := range c.musicas {
+		b, ok := c.generosBits[m.Genero]
+		if !ok {
+			b = novoBitset(len(c.musicas))
+			c.generosBits[m.Genero] = b
+		}
+		b.adiciona(p)
+	}
+}
+
+// bitsGeneros retorna o bitset com as posições das músicas que pertencem a
+// algum dos gêneros passados.
+func (c *Catalog) bitsGeneros(generos sets.Set) bitset {
+	b := novoBitset(len(c.musicas))
+	for _, g := range generos.ToSlice() {
+		if porGenero, ok := c.generosBits[g.(string)]; ok {
+			b.une(porGenero)
+		}
+	}
+	return b
+}
+
+// bits retorna o bitset com os termos conhecidos pelo dicionário da
+// comparação. Termos ausentes do catálogo são ignorados.
+func (c *Catalog) bits(comparacao int, termos sets.Set) bitset {
+	d := c.dicionarios[comparacao]
+	var b bitset
+	for t := range termos.Iter() {
+		if n, ok := d.numeros[t.(string)]; ok {
+			b.adiciona(n)
+		}
+	}
+	return b
+}
+
+// Consulta descreve uma busca por músicas similares a um conjunto de termos.
+type Consulta struct {
+	// Termos da consulta, na forma da comparação (veja Musica.Termo).
+	Termos     sets.Set
+	Comparacao int
+	// Caso não seja vazio, apenas músicas de algum dos gêneros são retornadas.
+	Generos sets.Set
+	// Caso verdadeiro, apenas músicas com no máximo Tolerancia termos fora
+	// da consulta são retornadas.
+	SomenteConhecidos bool
+	Tolerancia        int
+	// Caso verdadeiro, preenche os pesos (IDF) das músicas similares.
+	Pesos bool
+}
+
+// Similar resume a comparação de uma música com a consulta.
+type Similar struct {
+	Musica *Musica
+	// Número de termos da música e número de termos em comum com a consulta.
+	Termos, Intersecao int
+	// Soma dos pesos dos termos em comum e da união com a consulta.
+	PesoIntersecao, PesoUniao float64
+}
+
+// Similares retorna as músicas com ao menos um termo em comum com a consulta,
+// ordenadas por popularidade. Músicas com um único acorde são ignoradas.
+func (c *Catalog) Similares(q Consulta) []Similar {
+	tolerancia := q.Tolerancia
+	if tolerancia < 0 {
+		tolerancia = 0
+	}
+	if tolerancia > MAX_TOLERANCIA {
+		tolerancia = MAX_TOLERANCIA
+	}
+
+	d := c.dicionarios[q.Comparacao]
+	consulta := c.bits(q.Comparacao, q.Termos)
+	// A soma percorre o bitset, em ordem, para que o resultado não dependa
+	// da ordem de iteração do conjunto.
+	pesoConsulta := float64(q.Termos.Cardinality()-consulta.cardinalidade()) * d.idfAusente
+	consulta.itera(func(n int) {
+		pesoConsulta += d.idf[n]
+	})
+
+	candidatas := novoBitset(len(c.musicas))
+	if q.SomenteConhecidos && q.Comparacao != COMPARA_GRAU {
+		// O índice de acordes raros é enarmônico. Na comparação por grafia,
+		// as músicas tocáveis considerando enarmônicos incluem as tocáveis
+		// considerando a grafia.
+		conhecidos := consulta
+		if q.Comparacao == COMPARA_GRAFIA {
+			chaves := sets.NewSet()
+			for a := range q.Termos.Iter() {
+				chave, _ := acorde.ChaveEnarmonica(a.(string))
+				chaves.Add(chave)
+			}
+			conhecidos = c.bits(COMPARA_ENARMONICO, chaves)
+		}
+		c.tocaveis(conhecidos, tolerancia, candidatas.adiciona)
+	} else {
+		consulta.itera(func(n int) {
+			for _, p := range d.musicas[n] {
+				candidatas.adiciona(int(p))
+			}
+		})
+	}
+	if q.Generos != nil && q.Generos.Cardinality() > 0 {
+		candidatas.intersecta(c.bitsGeneros(q.Generos))
+	}
+
+	var similares []Similar
+	candidatas.itera(func(p int) {
+		m := c.musicas[p]
+		if m.termos[COMPARA_GRAFIA].cardinalidade() <= 1 {
+			return
+		}
+		termos := m.termos[q.Comparacao]
+		intersecao := termos.intersecao(consulta)
+		if intersecao == 0 {
+			return
+		}
+		n := termos.cardinalidade()
+		if q.SomenteConhecidos && n-intersecao > tolerancia {
+			return
+		}
+		s := Similar{Musica: m, Termos: n, Intersecao: intersecao}
+		if q.Pesos {
+			s.PesoUniao = pesoConsulta
+			termos.itera(func(t int) {
+				if consulta.contem(t) {
+					s.PesoIntersecao += d.idf[t]
+				} else {
+					s.PesoUniao += d.idf[t]
+				}
+			})
+		}
+		similares = append(similares, s)
+	})
+	return similares
+}
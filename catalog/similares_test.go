@@ -0,0 +1,73 @@
+package catalog
+
+import (
+	"testing"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+// similaresComSets reproduz a busca por músicas similares feita com os
+// conjuntos de ids do catálogo, usada como referência nos benchmarks.
+func similaresComSets(c *Catalog, consulta, generos sets.Set) int {
+	candidatas := sets.NewSet()
+	for a := range consulta.Iter() {
+		if m, ok := c.PorAcorde(a.(string)); ok {
+			candidatas = candidatas.Union(m)
+		}
+	}
+	if generos.Cardinality() > 0 {
+		porGenero := sets.NewSet()
+		for g := range generos.Iter() {
+			if m, ok := c.PorGenero(g.(string)); ok {
+				porGenero = porGenero.Union(m)
+			}
+		}
+		candidatas = candidatas.Intersect(porGenero)
+	}
+	n := 0
+	for id := range candidatas.Iter() {
+		m := c.musicasDict[id.(string)]
+		acordes := m.AcordesEnarmonicos()
+		if acordes.Cardinality() > 1 && acordes.Intersect(consulta).Cardinality() > 0 {
+			n++
+		}
+	}
+	return n
+}
+
+var consultasBenchmark = []struct {
+	nome             string
+	acordes, generos []string
+}{
+	{"comuns", []string{"Am", "F", "C", "G"}, nil},
+	{"comuns_genero", []string{"Am", "F", "C", "G"}, []string{"Rock", "MPB"}},
+	{"raros", []string{"Bm7(b5)", "E°", "C7M"}, nil},
+}
+
+func BenchmarkSimilares(b *testing.B) {
+	c := catalogSintetico(20000, palavrasSinteticas)
+	for _, q := range consultasBenchmark {
+		consulta, generos := sets.NewSet(), sets.NewSet()
+		for _, a := range q.acordes {
+			consulta.Add(a)
+		}
+		for _, g := range q.generos {
+			generos.Add(g)
+		}
+		if n, esperado := len(c.Similares(Consulta{Termos: consulta, Generos: generos})), similaresComSets(c, consulta, generos); n != esperado {
+			b.Fatalf("%s: bitsets retornaram %d músicas, conjuntos retornaram %d", q.nome, n, esperado)
+		}
+		b.Run(q.nome+"/sets", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				similaresComSets(c, consulta, generos)
+			}
+		})
+		b.Run(q.nome+"/bitsets", func(b *testing.B) {
+			b.ReportAllocs()
+			for i := 0; i < b.N; i++ {
+				c.Similares(Consulta{Termos: consulta, Generos: generos})
+			}
+		})
+	}
+}
@@ -0,0 +1,144 @@
+package catalog
+
+import (
+	"sort"
+	"strings"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+const (
+	// Número padrão e número máximo de sugestões retornadas.
+	NUM_SUGESTOES = 10
+	MAX_SUGESTOES = 50
+)
+
+// Tipos de sugestão do autocompletar.
+const (
+	SUGESTAO_ARTISTA = "artista"
+	SUGESTAO_MUSICA  = "musica"
+)
+
+// Sugestao é um artista ou uma música sugerida pelo autocompletar.
+type Sugestao struct {
+	Tipo string `json:"tipo"`
+	// Id do artista ou id único da música.
+	ID   string `json:"id"`
+	Nome string `json:"nome"`
+	// Nome do artista, no caso das músicas.
+	Artista string `json:"nome_artista,omitempty"`
+	// Popularidade da música ou soma das popularidades das músicas do
+	// artista.
+	Popularidade int `json:"popularidade"`
+
+	musica  *Musica
+	artista *Artista
+}
+
+// pertence retorna verdadeiro se a sugestão possui músicas de algum dos
+// gêneros passados.
+func (s *Sugestao) pertence(generos sets.Set) bool {
+	if s.musica != nil {
+		return generos.Contains(s.musica.Genero)
+	}
+	for _, g := range s.artista.Generos {
+		if generos.Contains(g) {
+			return true
+		}
+	}
+	return false
+}
+
+// prefixo associa a normalização de um nome, a partir de uma das suas
+// palavras, à sugestão.
+type prefixo struct {
+	chave    string
+	sugestao int32
+}
+
+// indexaSugestoes constrói a estrutura de prefixos do autocompletar: as
+// sugestões ordenadas por popularidade e, em ordem alfabética, os nomes
+// normalizados a partir de cada palavra ("legiao urbana" e "urbana"). As
+// sugestões de um prefixo ocupam um intervalo contíguo dos nomes, encontrado
+// por busca binária. Os artistas já devem estar indexados.
+func (c *Catalog) indexaSugestoes() {
+	c.sugestoes = nil
+	for _, a := range c.artistas {
+		c.sugestoes = append(c.sugestoes, &Sugestao{
+			Tipo:         SUGESTAO_ARTISTA,
+			ID:           a.ID,
+			Nome:         a.Nome,
+			Popularidade: a.Popularidade,
+			artista:      a,
+		})
+	}
+	for _, m := range c.musicas {
+		c.sugestoes = append(c.sugestoes, &Sugestao{
+			Tipo:         SUGESTAO_MUSICA,
+			ID:           m.UniqueID,
+			Nome:         m.Nome,
+			Artista:      m.Artista,
+			Popularidade: m.Popularidade,
+			musica:       m,
+		})
+	}
+	sort.SliceStable(c.sugestoes, func(i, j int) bool {
+		return c.sugestoes[i].Popularidade > c.sugestoes[j].Popularidade
+	})
+
+	c.prefixos = nil
+	for i, s := range c.sugestoes {
+		termos := termosBusca(s.Nome)
+		for j := range termos {
+			c.prefixos = append(c.prefixos, prefixo{strings.Join(termos[j:], " "), int32(i)})
+		}
+	}
+	sort.Slice(c.prefixos, func(i, j int) bool {
+		if c.prefixos[i].chave != c.prefixos[j].chave {
+			return c.prefixos[i].chave < c.prefixos[j].chave
+		}
+		return c.prefixos[i].sugestao < c.prefixos[j].sugestao
+	})
+}
+
+// Sugestoes retorna os n artistas e músicas mais populares cujo nome possui
+// uma palavra iniciada pela consulta, sem distinção de maiúsculas e acentos.
+// Apenas artistas e músicas dos gêneros passados são considerados (todos,
+// caso nenhum gênero seja passado).
+func (c *Catalog) Sugestoes(consulta string, generos sets.Set, n int) []*Sugestao {
+	chave := strings.Join(termosBusca(consulta), " ")
+	if chave == "" || n <= 0 {
+		return nil
+	}
+	inicio := sort.Search(len(c.prefixos), func(i int) bool {
+		return c.prefixos[i].chave >= chave
+	})
+	// As sugestões são ordenadas por popularidade: as n mais populares são as
+	// de menor índice.
+	var melhores []int32
+	for _, p := range c.prefixos[inicio:] {
+		if !strings.HasPrefix(p.chave, chave) {
+			break
+		}
+		if len(melhores) == n && p.sugestao >= melhores[n-1] {
+			continue
+		}
+		i := sort.Search(len(melhores), func(i int) bool { return melhores[i] >= p.sugestao })
+		if i < len(melhores) && melhores[i] == p.sugestao {
+			continue
+		}
+		if generos.Cardinality() > 0 && !c.sugestoes[p.sugestao].pertence(generos) {
+			continue
+		}
+		if len(melhores) < n {
+			melhores = append(melhores, 0)
+		}
+		copy(melhores[i+1:], melhores[i:])
+		melhores[i] = p.sugestao
+	}
+	sugestoes := make([]*Sugestao, len(melhores))
+	for i, s := range melhores {
+		sugestoes[i] = c.sugestoes[s]
+	}
+	return sugestoes
+}
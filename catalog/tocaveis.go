@@ -0,0 +1,76 @@
+package catalog
+
+import (
+	sets "github.com/deckarep/golang-set"
+)
+
+// Número máximo de acordes desconhecidos admitido na busca por músicas
+// tocáveis.
+const MAX_TOLERANCIA = 3
+
+// indexaRaros indexa cada música pelos seus MAX_TOLERANCIA+1 acordes menos
+// frequentes no catálogo.
+//
+// Se uma música possui no máximo n acordes fora de um conjunto, ao menos um
+// dos seus n+1 acordes mais raros pertence ao conjunto. Dessa forma, a busca
+// por músicas tocáveis só precisa considerar as músicas indexadas pelos
+// acordes conhecidos nas n+1 primeiras posições, que são bem menos numerosas
+// que as músicas que possuem algum dos acordes conhecidos (acordes comuns,
+// como G, raramente são os mais raros de uma música).
+//
+// Os acordes são identificados pelo número no dicionário enarmônico, que
+// cresce à medida que os acordes ficam mais raros.
+func (c *Catalog) indexaRaros() {
+	d := c.dicionarios[COMPARA_ENARMONICO]
+	for i := range c.musicasPorRaro {
+		c.musicasPorRaro[i] = make([][]int32, len(d.idf))
+	}
+	for p, m := range c.musicas {
+		var acordes []int
+		m.termos[COMPARA_ENARMONICO].itera(func(n int) {
+			acordes = append(acordes, n)
+		})
+		for i := 0; i <= MAX_TOLERANCIA && i < len(acordes); i++ {
+			n := acordes[len(acordes)-1-i]
+			c.musicasPorRaro[i][n] = append(c.musicasPorRaro[i][n], int32(p))
+		}
+	}
+}
+
+// tocaveis chama f com a posição de cada música que possui algum dos acordes
+// conhecidos (no dicionário enarmônico) e no máximo tolerancia acordes fora
+// desse conjunto.
+func (c *Catalog) tocaveis(conhecidos bitset, tolerancia int, f func(p int)) {
+	vistas := novoBitset(len(c.musicas))
+	for i := 0; i <= tolerancia; i++ {
+		conhecidos.itera(func(n int) {
+			for _, p := range c.musicasPorRaro[i][n] {
+				if vistas.contem(int(p)) {
+					continue
+				}
+				vistas.adiciona(int(p))
+				if c.musicas[p].termos[COMPARA_ENARMONICO].diferenca(conhecidos) <= tolerancia {
+					f(int(p))
+				}
+			}
+		})
+	}
+}
+
+// Tocaveis retorna os ids das músicas que possuem algum dos acordes
+// conhecidos e no máximo tolerancia acordes fora desse conjunto. Os acordes
+// conhecidos devem estar na forma enarmônica canônica. A tolerância é
+// limitada a MAX_TOLERANCIA.
+func (c *Catalog) Tocaveis(conhecidos sets.Set, tolerancia int) sets.Set {
+	if tolerancia < 0 {
+		tolerancia = 0
+	}
+	if tolerancia > MAX_TOLERANCIA {
+		tolerancia = MAX_TOLERANCIA
+	}
+	tocaveis := sets.NewSet()
+	c.tocaveis(c.bits(COMPARA_ENARMONICO, conhecidos), tolerancia, func(p int) {
+		tocaveis.Add(c.musicas[p].UniqueID)
+	})
+	return tocaveis
+}
@@ -0,0 +1,118 @@
+package catalog
+
+import (
+	"testing"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	sets "github.com/deckarep/golang-set"
+)
+
+// Catálogo das buscas por músicas tocáveis. E7 e B7 são os acordes mais raros
+// de d, e A# (grafado como Bb em b) é o mais raro de c.
+var musicasTocaveis = []string{
+	"x,a,X,A,Rock,800,C,NA,C;G;Am;F",
+	"x,b,X,B,Rock,700,C,NA,C;G;Am;F;Bb",
+	"x,c,X,C,Rock,600,C,NA,A#;C;G",
+	"x,d,X,D,Rock,500,C,NA,C;G;Am;F;Dm;E7;B7",
+	"y,e,Y,E,MPB,400,D,NA,D;A;Bm;G",
+	"y,f,Y,F,MPB,300,Gb,NA,Gb;Db;Ebm;Cb",
+	"y,g,Y,G,MPB,200,C,NA,C;C",
+	"y,h,Y,H,MPB,100,C,NA,C;Am;F;G;C#m7;F#m7(b5);Bb;G#°",
+}
+
+var consultasTocaveis = [][]string{
+	{"C", "G", "Am", "F"},
+	{"C", "G", "Am", "F", "Bb"},
+	{"C", "G", "A#"},
+	{"C", "G", "Am", "F", "Dm"},
+	{"D", "A"},
+	{"F#", "C#", "D#m", "B"},
+	{"Em"},
+}
+
+// tocaveisForcaBruta retorna os ids das músicas com algum dos termos
+// conhecidos e no máximo tolerancia termos fora deles, comparando o conjunto
+// de termos de cada música.
+func tocaveisForcaBruta(c *Catalog, termos func(m *Musica) sets.Set, conhecidos sets.Set, tolerancia int) sets.Set {
+	tocaveis := sets.NewSet()
+	for _, m := range c.musicas {
+		t := termos(m)
+		if t.Intersect(conhecidos).Cardinality() > 0 && t.Difference(conhecidos).Cardinality() <= tolerancia {
+			tocaveis.Add(m.UniqueID)
+		}
+	}
+	return tocaveis
+}
+
+func TestTocaveis(t *testing.T) {
+	c := catalogTeste(t, musicasTocaveis...)
+	for _, consulta := range consultasTocaveis {
+		conhecidos := sets.NewSet()
+		for _, a := range consulta {
+			chave, err := acorde.ChaveEnarmonica(a)
+			if err != nil {
+				t.Fatal(err)
+			}
+			conhecidos.Add(chave)
+		}
+		for tolerancia := 0; tolerancia <= MAX_TOLERANCIA; tolerancia++ {
+			got := c.Tocaveis(conhecidos, tolerancia)
+			want := tocaveisForcaBruta(c, (*Musica).AcordesEnarmonicos, conhecidos, tolerancia)
+			if !got.Equal(want) {
+				t.Errorf("Tocaveis(%v, %d) = %v, want %v", consulta, tolerancia, got, want)
+			}
+		}
+	}
+
+	// d só é tocável com os seus dois acordes mais raros desconhecidos.
+	conhecidos := sets.NewSet("C", "G", "Am", "F", "Dm")
+	for tolerancia, want := range []bool{false, false, true, true} {
+		if got := c.Tocaveis(conhecidos, tolerancia).Contains("x_d"); got != want {
+			t.Errorf("Tocaveis(%v, %d) contém x_d = %v, want %v", conhecidos, tolerancia, got, want)
+		}
+	}
+}
+
+// A busca por músicas similares com somente_conhecidos usa o índice de
+// acordes raros também na comparação por grafia, na qual A# e Bb são acordes
+// diferentes.
+func TestSimilaresSomenteConhecidos(t *testing.T) {
+	c := catalogTeste(t, musicasTocaveis...)
+	for _, comparacao := range []int{COMPARA_ENARMONICO, COMPARA_GRAFIA} {
+		for _, consulta := range consultasTocaveis {
+			termos := sets.NewSet()
+			for _, a := range consulta {
+				if comparacao == COMPARA_ENARMONICO {
+					a, _ = acorde.ChaveEnarmonica(a)
+				}
+				termos.Add(a)
+			}
+			for tolerancia := 0; tolerancia <= MAX_TOLERANCIA; tolerancia++ {
+				got := sets.NewSet()
+				for _, s := range c.Similares(Consulta{Termos: termos, Comparacao: comparacao, SomenteConhecidos: true, Tolerancia: tolerancia}) {
+					got.Add(s.Musica.UniqueID)
+				}
+				want := tocaveisForcaBruta(c, func(m *Musica) sets.Set {
+					if comparacao == COMPARA_GRAFIA {
+						return m.Acordes()
+					}
+					return m.AcordesEnarmonicos()
+				}, termos, tolerancia)
+				// Músicas com um único acorde não são similares a nenhuma
+				// consulta.
+				want.Remove("y_g")
+				if !got.Equal(want) {
+					t.Errorf("Similares(%v, comparação %d, tolerância %d) = %v, want %v", consulta, comparacao, tolerancia, got, want)
+				}
+			}
+		}
+	}
+
+	// Na comparação por grafia, o A# de c não é conhecido por quem sabe Bb.
+	q := Consulta{Termos: sets.NewSet("C", "G", "Bb"), Comparacao: COMPARA_GRAFIA, SomenteConhecidos: true}
+	for _, s := range c.Similares(q) {
+		if s.Musica.UniqueID == "x_c" {
+			t.Errorf("Similares(%v) contém x_c", q.Termos)
+		}
+	}
+}
@@ -0,0 +1,115 @@
+package catalog
+
+import (
+	"fmt"
+	"io"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	sets "github.com/deckarep/golang-set"
+)
+
+// Tipos de problema reportados pela validação do dataset.
+const (
+	LINHA_INVALIDA     = "linha_invalida"
+	CIFRA_VAZIA        = "cifra_vazia"
+	ID_DUPLICADO       = "id_duplicado"
+	ACORDE_INVALIDO    = "acorde_invalido"
+	TOM_INVALIDO       = "tom_invalido"
+	TOM_DIVERGENTE     = "tom_divergente"
+	SEQUENCIA_INVALIDA = "sequencia_invalida"
+)
+
+// Problema descreve um problema de qualidade encontrado no dataset.
+type Problema struct {
+	Linha    int
+	UniqueID string
+	Tipo     string
+	Detalhe  string
+}
+
+func (p Problema) String() string {
+	return fmt.Sprintf("linha %d\t%s\t%s\t%s", p.Linha, p.UniqueID, p.Tipo, p.Detalhe)
+}
+
+// Relatorio é o resultado da validação de um dataset.
+type Relatorio struct {
+	Musicas   int
+	Problemas []Problema
+}
+
+// PorTipo retorna o número de problemas encontrados de cada tipo.
+func (r *Relatorio) PorTipo() map[string]int {
+	contagem := make(map[string]int)
+	for _, p := range r.Problemas {
+		contagem[p.Tipo]++
+	}
+	return contagem
+}
+
+// Valida lê o dataset de r da mesma forma que New e reporta os problemas de
+// qualidade encontrados. sequencias contém os ids válidos de sequências
+// famosas.
+func Valida(r io.Reader, sequencias sets.Set) (*Relatorio, error) {
+	relatorio := &Relatorio{}
+	linhaPorID := make(map[string]int)
+	d := NewReader(r)
+	for {
+		m, err := d.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			erroLinha, ok := err.(*ErroLinha)
+			if !ok {
+				return nil, err
+			}
+			relatorio.Problemas = append(relatorio.Problemas, Problema{
+				Linha:   erroLinha.Linha,
+				Tipo:    LINHA_INVALIDA,
+				Detalhe: erroLinha.Err.Error(),
+			})
+			if d.colunas == nil {
+				// Cabeçalho inválido, não é possível continuar.
+				break
+			}
+			continue
+		}
+		relatorio.Musicas++
+
+		linha := d.Linha()
+		problema := func(tipo, detalhe string) {
+			relatorio.Problemas = append(relatorio.Problemas, Problema{
+				Linha:    linha,
+				UniqueID: m.UniqueID,
+				Tipo:     tipo,
+				Detalhe:  detalhe,
+			})
+		}
+		if l, ok := linhaPorID[m.UniqueID]; ok {
+			problema(ID_DUPLICADO, fmt.Sprintf("também presente na linha %d", l))
+		} else {
+			linhaPorID[m.UniqueID] = linha
+		}
+		if len(m.Cifra) == 0 {
+			problema(CIFRA_VAZIA, "")
+		}
+		invalidos := sets.NewSet()
+		for _, a := range m.AcordesInvalidos {
+			if invalidos.Add(a) {
+				problema(ACORDE_INVALIDO, fmt.Sprintf("%q", a))
+			}
+		}
+		if _, err := acorde.ParseTom(m.Tom); m.Tom != "" && err != nil {
+			problema(TOM_INVALIDO, fmt.Sprintf("%q", m.Tom))
+		}
+		if m.estimaTom(); m.TomDivergente {
+			problema(TOM_DIVERGENTE, fmt.Sprintf("%q, estimado %q (confiança %.2f)", m.Tom, m.TomEstimado, m.ConfiancaTom))
+		}
+		for _, seq := range m.SeqFamosas {
+			if !sequencias.Contains(seq) {
+				problema(SEQUENCIA_INVALIDA, fmt.Sprintf("%q", seq))
+			}
+		}
+	}
+	return relatorio, nil
+}
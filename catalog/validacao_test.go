@@ -0,0 +1,37 @@
+package catalog
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	sets "github.com/deckarep/golang-set"
+)
+
+func TestValida(t *testing.T) {
+	dataset := `ARTISTA_ID,MUSICA_ID,ARTISTA,MUSICA,GENERO,POPULARIDADE,TOM,SEQ_FAMOSA,CIFRA
+v,a,V,A,Rock,500,C,1,C;F;G7;C
+v,b,V,B,Rock,400,D,NA,C;F;G7;C
+v,a,V,A,Rock,300,H,9,C;X
+v,c,V,C,Rock,200,C,NA,NA
+a,b,c
+`
+	r, err := Valida(strings.NewReader(dataset), sets.NewSet("1"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if r.Musicas != 4 {
+		t.Errorf("Musicas = %d, want 4", r.Musicas)
+	}
+	var got []string
+	for _, p := range r.Problemas {
+		got = append(got, p.Tipo)
+	}
+	want := []string{TOM_DIVERGENTE, ID_DUPLICADO, ACORDE_INVALIDO, TOM_INVALIDO, SEQUENCIA_INVALIDA, CIFRA_VAZIA, LINHA_INVALIDA}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Problemas = %v, want %v", r.Problemas, want)
+	}
+	if p := r.Problemas[0]; p.Linha != 3 || p.UniqueID != "v_b" {
+		t.Errorf("Problemas[0] = %v, want linha 3, v_b", p)
+	}
+}
@@ -0,0 +1,52 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+)
+
+// ListaComFacetas é a resposta das listas (/musicas, /search e /similares)
+// quando as facetas são pedidas: a página de resultados e as contagens sobre
+// todos os resultados da consulta.
+type ListaComFacetas struct {
+	Resultados interface{} `json:"resultados"`
+	Facetas    Facetas     `json:"facetas"`
+}
+
+type Facetas struct {
+	// Número de músicas de cada gênero, ordenados pelo número de músicas.
+	Generos []catalog.Frequencia `json:"generos"`
+}
+
+// facetasFromRequest retorna se as facetas foram pedidas com
+// facetas=generos, o único tipo de faceta suportado.
+func facetasFromRequest(r *http.Request) (bool, error) {
+	switch r.URL.Query().Get("facetas") {
+	case "":
+		return false, nil
+	case "generos":
+		return true, nil
+	default:
+		return false, fmt.Errorf("faceta inválida: %q", r.URL.Query().Get("facetas"))
+	}
+}
+
+// calculaFacetas conta os gêneros dos resultados da consulta, caso as facetas
+// tenham sido pedidas. Retorna nil caso contrário.
+func calculaFacetas(pedidas bool, resultados []*catalog.Musica) *Facetas {
+	if !pedidas {
+		return nil
+	}
+	return &Facetas{catalog.FacetasGeneros(resultados)}
+}
+
+// comFacetas acompanha a página de resultados das facetas, caso tenham sido
+// calculadas.
+func comFacetas(pagina interface{}, facetas *Facetas) interface{} {
+	if facetas == nil {
+		return pagina
+	}
+	return ListaComFacetas{pagina, *facetas}
+}
@@ -4,36 +4,72 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"sort"
+	"sync/atomic"
 
-	sets "github.com/deckarep/golang-set"
+	"github.com/danielfireman/ciframe-api/catalog"
 	"github.com/julienschmidt/httprouter"
 	"github.com/newrelic/go-agent"
 )
 
 type Generos struct {
-	app      newrelic.Application
-	jsonData string
+	app   newrelic.Application
+	ref   *catalog.Ref
+	lista atomic.Value // *listaGeneros
 }
 
-func NewGeneros(app newrelic.Application, generosSet sets.Set) (*Generos, error) {
-	var generos []string
-	for _, i := range generosSet.ToSlice() {
-		generos = append(generos, i.(string))
+// listaGeneros é a resposta do handler, já serializada, e o catálogo a
+// partir do qual foi calculada.
+type listaGeneros struct {
+	catalog *catalog.Catalog
+	json    string
+}
+
+func NewGeneros(app newrelic.Application, ref *catalog.Ref) (*Generos, error) {
+	g := &Generos{app: app, ref: ref}
+	l, err := g.prepara(ref.Get())
+	if err != nil {
+		return nil, err
 	}
-	sort.Sort(sort.StringSlice(generos))
-	b, err := json.Marshal(generos)
+	g.publica(l)
+	return g, nil
+}
+
+// prepara calcula a lista de gêneros do catálogo, sem publicá-la.
+func (g *Generos) prepara(c *catalog.Catalog) (*listaGeneros, error) {
+	b, err := json.Marshal(c.EstatisticasGeneros())
 	if err != nil {
 		return nil, err
 	}
-	return &Generos{app, string(b)}, err
+	return &listaGeneros{c, string(b)}, nil
+}
+
+// publica passa a retornar a lista de gêneros preparada.
+func (g *Generos) publica(l *listaGeneros) {
+	g.lista.Store(l)
 }
 
+// Retorna os gêneros do catálogo, ordenados pelo nome, com o número de
+// músicas e de artistas, os tons e acordes mais comuns e o número médio de
+// acordes distintos por música. Grafias diferentes do mesmo gênero no dataset
+// são agrupadas e listadas em variantes.
+// exemplo: /generos
 func (g *Generos) GetHandler() httprouter.Handle {
 	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 		txn := g.app.StartTransaction("generos", w, r)
 		defer txn.End()
+		// A lista acompanha o catálogo em uso: caso o catálogo tenha sido
+		// substituído antes da publicação da nova lista, ela é recalculada.
+		c := g.ref.Get()
+		l := g.lista.Load().(*listaGeneros)
+		if l.catalog != c {
+			var err error
+			if l, err = g.prepara(c); err != nil {
+				w.WriteHeader(http.StatusInternalServerError)
+				return
+			}
+			g.publica(l)
+		}
 		w.Header().Add("Access-Control-Allow-Origin", "*")
-		fmt.Fprintf(w, g.jsonData)
+		fmt.Fprint(w, l.json)
 	}
 }
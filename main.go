@@ -4,13 +4,13 @@ import (
 	"encoding/json"
 	"fmt"
 	"log"
-	"math"
 	"net/http"
 	"net/url"
 	"os"
 	"strconv"
 	"strings"
 
+	"github.com/danielfireman/ciframe-api/catalog"
 	sets "github.com/deckarep/golang-set"
 	"github.com/julienschmidt/httprouter"
 	"github.com/newrelic/go-agent"
@@ -19,6 +19,10 @@ import (
 )
 
 func main() {
+	if len(os.Args) > 1 && os.Args[1] == "validate" {
+		os.Exit(validate(os.Args[2:]))
+	}
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		log.Fatal("$PORT must be set")
@@ -42,11 +46,16 @@ func main() {
 	}
 	log.Println("Redis cache conectado.")
 
-	loadData()
+	c, err := catalog.FromFile(DATASET)
+	if err != nil {
+		log.Fatal(err)
+	}
+	logLinhasInvalidas(c)
+	ref := catalog.NewRef(c)
 	log.Println("Dados carregados com sucesso.")
 
 	router := httprouter.New()
-	g, err := NewGeneros(app, generosSet)
+	g, err := NewGeneros(app, ref)
 	if err != nil {
 		log.Fatal(err)
 	}
@@ -54,26 +63,75 @@ func main() {
 	router.OPTIONS("/generos", openCORS)
 
 	// Controlando o acesso concorrente: 5 requisições por segundo.
-	s := Similares{app, make(chan struct{}, 5), redisCache}
+	s := &Similares{app, make(chan struct{}, 5), redisCache, ref}
 	router.GET("/similares", s.GetHandler())
 	router.OPTIONS("/similares", openCORS)
 
-	router.GET("/search", MonitoredEndpoint(app, "search", SearchHandler))
+	// O dataset pode ser recarregado enviando SIGHUP ao processo ou, caso
+	// $ADMIN_TOKEN esteja definido, via POST /admin/recarregar.
+	rc := &Recarregador{dataset: DATASET, ref: ref, generos: g, similares: s, token: os.Getenv("ADMIN_TOKEN")}
+	go rc.AguardaSinal()
+	if rc.token != "" {
+		router.POST("/admin/recarregar", MonitoredEndpoint(app, "admin_recarregar", rc.PostHandler()))
+	}
+
+	router.GET("/search", MonitoredEndpoint(app, "search", SearchHandler(ref)))
 	router.OPTIONS("/search", MonitoredEndpoint(app, "search_cors", openCORS))
 
-	router.GET("/musicas", MonitoredEndpoint(app, "musicas", MusicasHandler))
+	router.GET("/musicas", MonitoredEndpoint(app, "musicas", MusicasHandler(ref)))
 	router.OPTIONS("/musicas", MonitoredEndpoint(app, "musicas_cors", openCORS))
 
-	router.GET("/musica/:id", MonitoredEndpoint(app, "get_musica", MusicasHandler))
+	router.GET("/musica/:id", MonitoredEndpoint(app, "get_musica", GetMusicaHandler(ref)))
 	router.OPTIONS("/musica/:id", MonitoredEndpoint(app, "get_musica_cors", openCORS))
 
-	router.GET("/acordes", MonitoredEndpoint(app, "acordes", AcordesHandler))
+	router.GET("/musica/:id/transpor", MonitoredEndpoint(app, "transpor", TransporHandler(ref)))
+	router.OPTIONS("/musica/:id/transpor", MonitoredEndpoint(app, "transpor_cors", openCORS))
+
+	router.GET("/capotraste", MonitoredEndpoint(app, "capotraste", CapotrasteHandler(ref)))
+	router.OPTIONS("/capotraste", MonitoredEndpoint(app, "capotraste_cors", openCORS))
+
+	router.GET("/proximo-acorde", MonitoredEndpoint(app, "proximo_acorde", ProximoAcordeHandler(ref)))
+	router.OPTIONS("/proximo-acorde", MonitoredEndpoint(app, "proximo_acorde_cors", openCORS))
+
+	router.GET("/trilha", MonitoredEndpoint(app, "trilha", TrilhaHandler(ref)))
+	router.OPTIONS("/trilha", MonitoredEndpoint(app, "trilha_cors", openCORS))
+
+	router.POST("/tom", MonitoredEndpoint(app, "tom", TomHandler))
+	router.OPTIONS("/tom", MonitoredEndpoint(app, "tom_cors", openCORS))
+
+	router.GET("/acordes", MonitoredEndpoint(app, "acordes", AcordesHandler(ref)))
 	router.OPTIONS("/acordes", MonitoredEndpoint(app, "acordes_cors", openCORS))
 
+	// Acordes com baixo (G/B) ocupam mais de um segmento do caminho.
+	router.GET("/acordes/*acorde", MonitoredEndpoint(app, "get_acorde", GetAcordeHandler(ref)))
+	router.OPTIONS("/acordes/*acorde", MonitoredEndpoint(app, "get_acorde_cors", openCORS))
+
+	router.GET("/progressao", MonitoredEndpoint(app, "progressao", ProgressaoHandler(ref)))
+	router.OPTIONS("/progressao", MonitoredEndpoint(app, "progressao_cors", openCORS))
+
+	router.GET("/sequencias", MonitoredEndpoint(app, "sequencias", SequenciasHandler(ref)))
+	router.OPTIONS("/sequencias", MonitoredEndpoint(app, "sequencias_cors", openCORS))
+
+	router.GET("/autocomplete", MonitoredEndpoint(app, "autocomplete", AutocompleteHandler(ref)))
+	router.OPTIONS("/autocomplete", MonitoredEndpoint(app, "autocomplete_cors", openCORS))
+
+	router.GET("/artistas", MonitoredEndpoint(app, "artistas", ArtistasHandler(ref)))
+	router.OPTIONS("/artistas", MonitoredEndpoint(app, "artistas_cors", openCORS))
+
+	router.GET("/artistas/:id", MonitoredEndpoint(app, "get_artista", GetArtistaHandler(ref)))
+	router.OPTIONS("/artistas/:id", MonitoredEndpoint(app, "get_artista_cors", openCORS))
+
+	router.GET("/artistas/:id/musicas", MonitoredEndpoint(app, "artista_musicas", ArtistaMusicasHandler(ref)))
+	router.OPTIONS("/artistas/:id/musicas", MonitoredEndpoint(app, "artista_musicas_cors", openCORS))
+
 	log.Println("Serviço inicializado na porta ", port)
 	log.Fatal(http.ListenAndServe(":"+port, router))
 }
 
+const (
+	DATASET = "data/dataset_final.csv"
+)
+
 func openCORS(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
 	w.Header().Set("Access-Control-Allow-Headers:", "accept, content-type")
 	w.Header().Set("Access-Control-Allow-Methods:", "POST")
@@ -88,61 +146,6 @@ func MonitoredEndpoint(app newrelic.Application, name string, h httprouter.Handl
 	}
 }
 
-const (
-	TAM_PAGINA = 100
-)
-
-type Musica struct {
-	IDArtista    string   `json:"id_artista"`
-	UniqueID     string   `json:"id_unico_musica"`
-	Genero       string   `json:"genero"`
-	ID           string   `json:"id_musica"`
-	Artista      string   `json:"nome_artista"`
-	Nome         string   `json:"nome_musica"`
-	URL          string   `json:"url"`
-	Popularidade int      `json:"popularidade"`
-	Cifra        []string `json:"cifra"`
-	SeqFamosas   []string `json:"seq_famosas"`
-	Tom          string   `json:"tom"`
-}
-
-func (m *Musica) Acordes() sets.Set {
-	acordes := sets.NewSet()
-	for _, c := range m.Cifra {
-		acordes.Add(c)
-	}
-	return acordes
-}
-
-func UniqueID(artista, id string) string {
-	return fmt.Sprintf("%s_%s", artista, id)
-}
-
-func URL(artista, id string) string {
-	return fmt.Sprintf("http://www.cifraclub.com.br/%s/%s", artista, id)
-}
-
-// PorPopularidade implementa sort.Interface for []*Musica baseado no campo Popularidade
-type PorPopularidade []*Musica
-
-func (p PorPopularidade) Len() int           { return len(p) }
-func (p PorPopularidade) Swap(i, j int)      { p[i], p[j] = p[j], p[i] }
-func (p PorPopularidade) Less(i, j int) bool { return p[i].Popularidade > p[j].Popularidade }
-
-var acordes []string
-var musicasDict = make(map[string]*Musica) // Mapa de músicas indexado por ids únicos.
-var generosSet = sets.NewSet()
-var musicas []*Musica // todas as músicas, ordenadas por popularidade.
-
-// Os conjuntos contém ids das músicas
-var musicasPorAcorde = make(map[string]sets.Set)
-var musicasPorGenero = make(map[string]sets.Set)
-
-func limitesDaPagina(size int, pagina int) (int, int) {
-	i := (pagina - 1) * TAM_PAGINA
-	return i, int(math.Min(float64(i+TAM_PAGINA), float64(size)))
-}
-
 func getPaginaFromRequest(r *http.Request) (int, error) {
 	pagina := 1
 	if r.URL.Query().Get("pagina") != "" {
@@ -155,30 +158,27 @@ func getPaginaFromRequest(r *http.Request) (int, error) {
 	return pagina, nil
 }
 
-// retorns generos do request (podem ser separados por vírgula).
-func generosFromRequest(r *http.Request) sets.Set {
+// retorns generos do request (podem ser separados por vírgula), com os nomes
+// usados no catálogo: generos=sertanejo e generos=Sertanejo são equivalentes.
+func generosFromRequest(r *http.Request, c *catalog.Catalog) sets.Set {
 	returned := sets.NewSet()
 	if r.URL.Query().Get("generos") != "" {
 		for _, g := range strings.Split(r.URL.Query().Get("generos"), ",") {
 			returned.Add(g)
 		}
 	}
-	return returned
+	return c.NormalizaGeneros(returned)
 }
 
-func applyFiltro(generos sets.Set) []*Musica {
-	if generos.Cardinality() == 0 {
-		return musicas
+// logLinhasInvalidas registra as linhas do dataset ignoradas na carga do
+// catálogo.
+func logLinhasInvalidas(c *catalog.Catalog) {
+	for _, e := range c.LinhasInvalidas() {
+		log.Printf("Linha do dataset ignorada: %v", e)
 	}
-	var collection []*Musica
-	for g := range generos.Iter() {
-		if generosSet.Contains(g.(string)) {
-			for m := range musicasPorGenero[g.(string)].Iter() {
-				collection = append(collection, musicasDict[m.(string)])
-			}
-		}
+	if n := len(c.LinhasInvalidas()); n > 0 {
+		log.Printf("%d linhas do dataset ignoradas.", n)
 	}
-	return collection
 }
 
 func Redis(u string) (*cache.Codec, error) {
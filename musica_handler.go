@@ -4,22 +4,116 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
+	"strings"
 
+	"github.com/danielfireman/ciframe-api/catalog"
 	"github.com/julienschmidt/httprouter"
 )
 
-func GetMusicaHandler(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
-	id := p.ByName("id")
-	m, ok := musicasDict[id]
-	if !ok {
-		w.WriteHeader(http.StatusNotFound)
-		return
+// Número de músicas similares retornadas no detalhe de uma música.
+const NUM_SIMILARES_DETALHE = 10
+
+type MusicaDetalheResponse struct {
+	*catalog.Musica
+	// Acordes únicos, na ordem em que aparecem na cifra, e suas estruturas.
+	Acordes    []string         `json:"acordes"`
+	Estruturas []AcordeResponse `json:"estruturas"`
+	// Sequências famosas informadas no dataset (seq_famosas) e mineradas do
+	// catálogo (veja /sequencias).
+	SequenciasFamosas []SequenciaFamosaResponse `json:"sequencias_famosas"`
+	Similares         []*SimilaresResponse      `json:"similares"`
+}
+
+type SequenciaFamosaResponse struct {
+	ID      string   `json:"id"`
+	Nome    string   `json:"nome"`
+	Acordes []string `json:"acordes"` // acordes da sequência, como aparecem na cifra.
+}
+
+// ErroResponse é o corpo das respostas de erro.
+type ErroResponse struct {
+	Erro string `json:"erro"`
+}
+
+// Retorna o detalhe de uma música: a cifra, os acordes únicos e suas
+// estruturas, as sequências famosas tocadas e as músicas mais similares
+// (calculadas como em /similares).
+// exemplo: /musica/legiao-urbana_tempo-perdido
+func GetMusicaHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		c := ref.Get()
+		id := p.ByName("id")
+		m, ok := c.Musica(id)
+		if !ok {
+			escreveErro(w, http.StatusNotFound, fmt.Sprintf("música não encontrada: %q", id))
+			return
+		}
+
+		resposta := MusicaDetalheResponse{
+			Musica:            m,
+			Acordes:           []string{},
+			Estruturas:        []AcordeResponse{},
+			SequenciasFamosas: []SequenciaFamosaResponse{},
+			Similares:         []*SimilaresResponse{},
+		}
+		vistos := make(map[string]bool)
+		for i, a := range m.Cifra {
+			if vistos[a] {
+				continue
+			}
+			vistos[a] = true
+			resposta.Acordes = append(resposta.Acordes, a)
+			resposta.Estruturas = append(resposta.Estruturas, AcordeResponse{a, m.Estruturas[i]})
+		}
+
+		for _, id := range m.SeqFamosas {
+			if acordes, ok := catalog.SequenciasDataset[id]; ok {
+				resposta.SequenciasFamosas = append(resposta.SequenciasFamosas, SequenciaFamosaResponse{
+					ID:      id,
+					Nome:    strings.Join(acordes, " "),
+					Acordes: acordes,
+				})
+			}
+		}
+		for _, s := range c.SequenciasTocadas(m) {
+			resposta.SequenciasFamosas = append(resposta.SequenciasFamosas, SequenciaFamosaResponse{
+				ID:      s.Sequencia.ID,
+				Nome:    strings.Join(s.Sequencia.Graus, " "),
+				Acordes: s.Trecho.Acordes,
+			})
+		}
+
+		consulta := m.AcordesEnarmonicos()
+		similares := ordenaSimilares(c, catalog.Consulta{
+			Termos:     consulta,
+			Comparacao: catalog.COMPARA_ENARMONICO,
+		}, m.UniqueID, estrategiasScore["diferenca"])
+		n := similares.Len()
+		if n > NUM_SIMILARES_DETALHE {
+			n = NUM_SIMILARES_DETALHE
+		}
+		if n > 0 {
+			resposta.Similares = similares.respostas(0, n, consulta, catalog.COMPARA_ENARMONICO, nil)
+		}
+
+		b, err := json.Marshal(resposta)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
 	}
-	b, err := json.Marshal(m)
+}
+
+// escreveErro responde com o status e uma mensagem de erro em JSON.
+func escreveErro(w http.ResponseWriter, status int, mensagem string) {
+	b, err := json.Marshal(ErroResponse{mensagem})
 	if err != nil {
 		w.WriteHeader(http.StatusInternalServerError)
 		return
 	}
 	w.Header().Add("Access-Control-Allow-Origin", "*")
-	fmt.Fprintf(w, string(b))
+	w.WriteHeader(status)
+	w.Write(b)
 }
@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	"github.com/danielfireman/ciframe-api/catalog"
+	"github.com/julienschmidt/httprouter"
+)
+
+type ProgressaoResponse struct {
+	UniqueID     string `json:"id_unico_musica"`
+	Artista      string `json:"nome_artista"`
+	Nome         string `json:"nome_musica"`
+	Popularidade int    `json:"popularidade"`
+	Genero       string `json:"genero"`
+	URL          string `json:"url"`
+	// Tom da música, usado na busca por graus.
+	Tom         string           `json:"tom,omitempty"`
+	Ocorrencias []catalog.Trecho `json:"ocorrencias"`
+}
+
+// Busca as músicas que tocam uma sequência de acordes, na ordem, ordenadas
+// por popularidade. Cada ocorrência traz as posições na cifra e os acordes
+// que formam a sequência.
+// A sequência pode ser dada por acordes ou por graus em relação ao tom de
+// cada música (como I,V,vi,IV), encontrando a progressão em qualquer tom.
+// Sequências com a tônica menor (i) são interpretadas na escala menor
+// natural: i,VII,VI equivale a i,bVII,bVI.
+// params: acordes ou graus, lacunas (opcional, até 3 acordes entre dois
+// acordes da sequência), ciclica (opcional, aceita qualquer rotação da
+// sequência), enarmonico (opcional), generos (opcional) e pagina (opcional).
+// exemplo 1: /progressao?acordes=Am,F,C,G
+// exemplo 2: /progressao?acordes=Am,F,C,G&ciclica=true&lacunas=1&generos=Rock
+// exemplo 3: /progressao?graus=i,VII,VI
+func ProgressaoHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		c := ref.Get()
+		queryValues := r.URL.Query()
+		pagina, err := getPaginaFromRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		consulta := catalog.ConsultaProgressao{
+			Comparacao: catalog.COMPARA_ENARMONICO,
+			Ciclica:    queryValues.Get("ciclica") == "true",
+			Generos:    generosFromRequest(r, c),
+		}
+		if queryValues.Get("enarmonico") == "false" {
+			consulta.Comparacao = catalog.COMPARA_GRAFIA
+		}
+		if queryValues.Get("lacunas") != "" {
+			consulta.Lacunas, err = strconv.Atoi(queryValues.Get("lacunas"))
+			if err != nil || consulta.Lacunas < 0 || consulta.Lacunas > catalog.MAX_LACUNAS {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+		}
+		switch {
+		case queryValues.Get("acordes") != "" && queryValues.Get("graus") == "":
+			for _, a := range strings.Split(queryValues.Get("acordes"), ",") {
+				estrutura, err := acorde.Parse(a)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if consulta.Comparacao == catalog.COMPARA_ENARMONICO {
+					estrutura = estrutura.Enarmonico()
+				}
+				consulta.Termos = append(consulta.Termos, estrutura.String())
+			}
+		case queryValues.Get("graus") != "" && queryValues.Get("acordes") == "":
+			consulta.Comparacao = catalog.COMPARA_GRAU
+			graus := strings.Split(queryValues.Get("graus"), ",")
+			menor := false
+			for _, g := range graus {
+				estrutura, err := acorde.ParseGrau(g, false)
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
+				if estrutura.Altura() == 0 && estrutura.Qualidade == acorde.MENOR {
+					menor = true
+				}
+			}
+			for _, g := range graus {
+				estrutura, _ := acorde.ParseGrau(g, menor)
+				consulta.Termos = append(consulta.Termos, estrutura.Grau(0))
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		ocorrencias := c.Progressoes(consulta)
+		i, f := catalog.LimitesDaPagina(len(ocorrencias), pagina)
+		resposta := []ProgressaoResponse{}
+		for _, o := range ocorrencias[i:f] {
+			var tom string
+			if consulta.Comparacao == catalog.COMPARA_GRAU {
+				t, _ := o.Musica.Tonalidade()
+				tom = t.String()
+			}
+			resposta = append(resposta, ProgressaoResponse{
+				UniqueID:     o.Musica.UniqueID,
+				Artista:      o.Musica.Artista,
+				Nome:         o.Musica.Nome,
+				Popularidade: o.Musica.Popularidade,
+				Genero:       o.Musica.Genero,
+				URL:          o.Musica.URL,
+				Tom:          tom,
+				Ocorrencias:  o.Trechos,
+			})
+		}
+		b, err := json.Marshal(resposta)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	"github.com/danielfireman/ciframe-api/catalog"
+	sets "github.com/deckarep/golang-set"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Número default de acordes recomendados.
+const NUM_PROXIMOS_ACORDES = 10
+
+// Recomenda os próximos acordes a aprender, ordenados pelo número de músicas
+// que cada um permite tocar quando combinado aos acordes já conhecidos.
+// params: acordes, generos (opcional), ponderado (opcional) e limite
+// (opcional). Com ponderado=true, as músicas contam de acordo com sua
+// popularidade.
+// exemplo 1: /proximo-acorde?acordes=C,G,Am,F
+// exemplo 2: /proximo-acorde?acordes=C,G,Am,F&generos=Rock&ponderado=true
+func ProximoAcordeHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		c := ref.Get()
+		queryValues := r.URL.Query()
+		conhecidos, ok := acordesFromRequest(r)
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		limite := NUM_PROXIMOS_ACORDES
+		if queryValues.Get("limite") != "" {
+			l, err := strconv.Atoi(queryValues.Get("limite"))
+			if err != nil || l < 1 {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			limite = l
+		}
+
+		candidatos := c.ProximosAcordes(conhecidos, generosFromRequest(r, c), queryValues.Get("ponderado") == "true")
+		if len(candidatos) > limite {
+			candidatos = candidatos[:limite]
+		}
+		b, err := json.Marshal(candidatos)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
+
+// acordesFromRequest retorna os acordes do request (separados por vírgula),
+// na forma enarmônica canônica. Retorna falso caso nenhum acorde válido seja
+// passado.
+func acordesFromRequest(r *http.Request) (sets.Set, bool) {
+	acordes := sets.NewSet()
+	if r.URL.Query().Get("acordes") != "" {
+		for _, a := range strings.Split(r.URL.Query().Get("acordes"), ",") {
+			if chave, err := acorde.ChaveEnarmonica(a); err == nil {
+				acordes.Add(chave)
+			}
+		}
+	}
+	return acordes, acordes.Cardinality() > 0
+}
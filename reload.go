@@ -0,0 +1,92 @@
+package main
+
+import (
+	"crypto/subtle"
+	"log"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Recarregador reconstrói o catálogo a partir do dataset e o substitui
+// atomicamente, sem reiniciar o servidor. Requisições em andamento terminam
+// de ser atendidas com a versão anterior do catálogo.
+type Recarregador struct {
+	dataset   string
+	ref       *catalog.Ref
+	generos   *Generos
+	similares *Similares
+	token     string // token exigido pelo endpoint de recarga.
+
+	mu sync.Mutex // serializa as recargas.
+}
+
+// Recarrega lê novamente o dataset e substitui o catálogo atual. Recargas
+// simultâneas são executadas uma após a outra.
+func (rc *Recarregador) Recarrega() error {
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
+
+	// Tudo o que é derivado do catálogo é calculado antes da substituição.
+	// Caso algo falhe, o catálogo e a lista de gêneros anteriores continuam
+	// em uso.
+	novo, err := catalog.FromFile(rc.dataset)
+	if err != nil {
+		return err
+	}
+	generos, err := rc.generos.prepara(novo)
+	if err != nil {
+		return err
+	}
+	logLinhasInvalidas(novo)
+
+	// O catálogo é publicado em uma única operação. Até que a nova lista de
+	// gêneros seja publicada, o handler de gêneros a recalcula a partir do
+	// catálogo em uso.
+	antigo := rc.ref.Get()
+	rc.ref.Set(novo)
+	rc.generos.publica(generos)
+	log.Printf("Dataset recarregado. Versão anterior: %s, nova versão: %s.", antigo.Versao(), novo.Versao())
+
+	if antigo.Versao() != novo.Versao() {
+		if err := rc.similares.InvalidaCache(antigo.Versao()); err != nil {
+			log.Printf("Erro invalidando o cache da versão %s: %q", antigo.Versao(), err)
+		}
+	}
+	return nil
+}
+
+// AguardaSinal recarrega o dataset sempre que o processo receber SIGHUP.
+func (rc *Recarregador) AguardaSinal() {
+	c := make(chan os.Signal, 1)
+	signal.Notify(c, syscall.SIGHUP)
+	for range c {
+		log.Println("SIGHUP recebido, recarregando dataset.")
+		if err := rc.Recarrega(); err != nil {
+			log.Printf("Erro recarregando dataset: %q", err)
+		}
+	}
+}
+
+// PostHandler dispara a recarga do dataset em background. A requisição deve
+// informar o token de administração no header X-Admin-Token.
+// exemplo: curl -X POST -H "X-Admin-Token: $ADMIN_TOKEN" /admin/recarregar
+func (rc *Recarregador) PostHandler() httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		if subtle.ConstantTimeCompare([]byte(r.Header.Get("X-Admin-Token")), []byte(rc.token)) != 1 {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		go func() {
+			if err := rc.Recarrega(); err != nil {
+				log.Printf("Erro recarregando dataset: %q", err)
+			}
+		}()
+		w.WriteHeader(http.StatusAccepted)
+	}
+}
@@ -0,0 +1,103 @@
+package main
+
+import (
+	"encoding/json"
+	"io/ioutil"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+	"gopkg.in/go-redis/cache.v4"
+)
+
+func recarregadorTeste(t *testing.T, dataset string) *Recarregador {
+	t.Helper()
+	c, err := catalog.FromFile(dataset)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ref := catalog.NewRef(c)
+	app := appTeste(t)
+	g, err := NewGeneros(app, ref)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Similares{
+		app:   app,
+		fila:  make(chan struct{}, 1),
+		cache: &cache.Codec{Redis: redisMemoria{}, Marshal: json.Marshal, Unmarshal: json.Unmarshal},
+		ref:   ref,
+	}
+	return &Recarregador{dataset: dataset, ref: ref, generos: g, similares: s}
+}
+
+// generosTeste retorna os gêneros listados pelo handler.
+func generosTeste(t *testing.T, g *Generos) []string {
+	t.Helper()
+	rec := httptest.NewRecorder()
+	g.GetHandler()(rec, httptest.NewRequest("GET", "/generos", nil), nil)
+	var estatisticas []catalog.EstatisticaGenero
+	if err := json.Unmarshal(rec.Body.Bytes(), &estatisticas); err != nil {
+		t.Fatalf("corpo %q: %v", rec.Body.String(), err)
+	}
+	var generos []string
+	for _, e := range estatisticas {
+		generos = append(generos, e.Genero)
+	}
+	return generos
+}
+
+func TestRecarrega(t *testing.T) {
+	dir, err := ioutil.TempDir("", "recarga")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	dataset := filepath.Join(dir, "dataset.csv")
+	if err := ioutil.WriteFile(dataset, []byte(datasetSimilares), 0644); err != nil {
+		t.Fatal(err)
+	}
+	rc := recarregadorTeste(t, dataset)
+
+	// Recargas simultâneas são serializadas e a lista de gêneros acompanha o
+	// novo catálogo.
+	novo := datasetSimilares + "c,quatro,C,Quatro,Forró,50,G,NA,G;C;D\n"
+	if err := ioutil.WriteFile(dataset, []byte(novo), 0644); err != nil {
+		t.Fatal(err)
+	}
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := rc.Recarrega(); err != nil {
+				t.Error(err)
+			}
+		}()
+	}
+	wg.Wait()
+	if _, ok := rc.ref.Get().Musica("c_quatro"); !ok {
+		t.Error("catálogo não foi substituído")
+	}
+	if got := generosTeste(t, rc.generos); len(got) != 3 {
+		t.Errorf("gêneros = %v, want Forró, Rock e Samba", got)
+	}
+
+	// Uma recarga que falha mantém o catálogo e os gêneros em uso.
+	antigo := rc.ref.Get()
+	if err := os.Remove(dataset); err != nil {
+		t.Fatal(err)
+	}
+	if err := rc.Recarrega(); err == nil {
+		t.Error("Recarrega() sem dataset: want erro")
+	}
+	if rc.ref.Get() != antigo {
+		t.Error("catálogo substituído por uma recarga que falhou")
+	}
+	if got := generosTeste(t, rc.generos); len(got) != 3 {
+		t.Errorf("gêneros = %v, want Forró, Rock e Samba", got)
+	}
+}
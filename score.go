@@ -0,0 +1,84 @@
+package main
+
+import (
+	"math"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+)
+
+// estrategiaScore calcula a similaridade entre uma consulta com n acordes e
+// uma música (acordes na forma usada na comparação). Quanto maior o score,
+// mais similar a música.
+type estrategiaScore func(n int, s catalog.Similar) float64
+
+// Estratégias de score disponíveis em /similares, selecionadas pelo
+// parâmetro score.
+var estrategiasScore = map[string]estrategiaScore{
+	// Menor número de acordes da música fora da consulta (default).
+	"diferenca": func(n int, s catalog.Similar) float64 {
+		return 1 / float64(1+s.Termos-s.Intersecao)
+	},
+	// Interseção sobre a união.
+	"jaccard": func(n int, s catalog.Similar) float64 {
+		uniao := n + s.Termos - s.Intersecao
+		if uniao == 0 {
+			return 0
+		}
+		return float64(s.Intersecao) / float64(uniao)
+	},
+	// Interseção sobre o tamanho do menor conjunto.
+	"sobreposicao": func(n int, s catalog.Similar) float64 {
+		menor := math.Min(float64(n), float64(s.Termos))
+		if menor == 0 {
+			return 0
+		}
+		return float64(s.Intersecao) / menor
+	},
+	// Jaccard ponderado pelo IDF dos acordes: acordes raros em comum pesam
+	// mais que acordes presentes em quase todas as músicas (como G).
+	"idf": func(n int, s catalog.Similar) float64 {
+		if s.PesoUniao == 0 {
+			return 0
+		}
+		return s.PesoIntersecao / s.PesoUniao
+	},
+}
+
+// PorScore implementa sort.Interface para []*SimilaresResponse baseado no
+// campo Score. Empates são desfeitos pela popularidade e, por fim, pelo id
+// único das músicas, de forma que a ordem seja determinística.
+type PorScore []*SimilaresResponse
+
+func (p PorScore) Len() int      { return len(p) }
+func (p PorScore) Swap(i, j int) { p[i], p[j] = p[j], p[i] }
+func (p PorScore) Less(i, j int) bool {
+	return precede(p[i].Score, p[i].Popularidade, p[i].UniqueID, p[j].Score, p[j].Popularidade, p[j].UniqueID)
+}
+
+// porScore ordena as músicas similares antes da construção das respostas,
+// com o mesmo critério de PorScore.
+type porScore struct {
+	similares []catalog.Similar
+	scores    []float64
+}
+
+func (p porScore) Len() int { return len(p.similares) }
+func (p porScore) Swap(i, j int) {
+	p.similares[i], p.similares[j] = p.similares[j], p.similares[i]
+	p.scores[i], p.scores[j] = p.scores[j], p.scores[i]
+}
+func (p porScore) Less(i, j int) bool {
+	mi, mj := p.similares[i].Musica, p.similares[j].Musica
+	return precede(p.scores[i], mi.Popularidade, mi.UniqueID, p.scores[j], mj.Popularidade, mj.UniqueID)
+}
+
+// precede retorna verdadeiro se a música a deve aparecer antes da música b.
+func precede(scoreA float64, popA int, idA string, scoreB float64, popB int, idB string) bool {
+	if scoreA != scoreB {
+		return scoreA > scoreB
+	}
+	if popA != popB {
+		return popA > popB
+	}
+	return idA < idB
+}
@@ -4,14 +4,9 @@ import (
 	"encoding/json"
 	"fmt"
 	"net/http"
-	"sort"
-	"strings"
-	"unicode"
 
+	"github.com/danielfireman/ciframe-api/catalog"
 	"github.com/julienschmidt/httprouter"
-
-	"golang.org/x/text/transform"
-	"golang.org/x/text/unicode/norm"
 )
 
 type SearchResponse struct {
@@ -24,87 +19,73 @@ type SearchResponse struct {
 	URL          string        `json:"url"`
 	Popularidade int           `json:"popularidade"`
 	Acordes      []interface{} `json:"acordes"`
+	// Termos da consulta e os termos do nome com os quais casaram, incluindo
+	// as correções de erros de digitação (tipo aproximado).
+	Termos []catalog.TermoEncontrado `json:"termos"`
+	// Relevância dos termos encontrados combinada com a popularidade.
+	Score float64 `json:"score"`
 }
 
 // Busca por músicas que possuem no título ou no nome do artista o argumento passado por key.
+// A busca tolera erros de digitação e aceita apenas o início da última palavra. Os resultados
+// são ordenados pela relevância dos termos encontrados combinada com a popularidade.
 // params: key e generos (opcional). Caso generos não sejam definidos, a busca não irá filtrar por gênero.
+// Com facetas=generos, a resposta traz também o número de músicas encontradas de cada gênero.
 // exemplo 1: /search?key=no dia em que eu saí de casa
 // exemplo 2: /search?key=no dia em que eu saí de casa&generos=Rock,Samba '''
-func SearchHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
-	generosABuscar := generosFromRequest(r)
-	pagina, err := getPaginaFromRequest(r)
-	if err != nil {
-		w.WriteHeader(http.StatusBadRequest)
-		return
-	}
-
-	queryValues := r.URL.Query()
-	keys := strings.Split(removerCombinantes(strings.ToLower(queryValues.Get("key"))), " ")
-	var musicasRes []*Musica
-	for _, m := range applyFiltro(generosABuscar) {
-		text := fmt.Sprintf("%s %s", strings.ToLower(m.Artista), strings.ToLower(m.Nome))
-		toCheck := make(map[string]struct{})
-		for _, t := range strings.Split(removerCombinantes(text), " ") {
-			toCheck[t] = struct{}{}
+// exemplo 3: /search?key=legiao urbna
+func SearchHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		c := ref.Get()
+		generosABuscar := generosFromRequest(r, c)
+		pagina, err := getPaginaFromRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
 		}
-		if all(keys, func(s string) bool {
-			_, ok := toCheck[s]
-			return ok
-		}) {
-			musicasRes = append(musicasRes, m)
+		facetas, err := facetasFromRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
 		}
-	}
-	// Quando não existem músicas, retorna um array vazio.
-	if len(musicasRes) == 0 {
-		fmt.Fprintf(w, "[]")
-		w.WriteHeader(http.StatusOK)
-		return
-	}
 
-	sort.Sort(PorPopularidade(musicasRes))
-	var resultado []SearchResponse
-	i, f := limitesDaPagina(len(musicasRes), pagina)
-	for _, m := range musicasRes[i:f] {
-		resultado = append(resultado, SearchResponse{
-			IDArtista:    m.IDArtista,
-			UniqueID:     m.UniqueID,
-			Genero:       m.Genero,
-			ID:           m.ID,
-			Artista:      m.Artista,
-			Nome:         m.Nome,
-			URL:          m.URL,
-			Popularidade: m.Popularidade,
-			Acordes:      m.Acordes().ToSlice(),
-		})
+		encontradas := c.Busca(r.URL.Query().Get("key"), generosABuscar)
+		musicasRes := make([]*catalog.Musica, len(encontradas))
+		for i, e := range encontradas {
+			musicasRes[i] = e.Musica
+		}
+		// Quando não existem músicas, retorna um array vazio.
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		if len(musicasRes) == 0 && !facetas {
+			fmt.Fprint(w, "[]")
+			return
+		}
 
-	}
-	b, err := json.Marshal(resultado)
-	if err != nil {
-		w.WriteHeader(http.StatusInternalServerError)
-		return
-	}
-	w.Header().Add("Access-Control-Allow-Origin", "*")
-	fmt.Fprintf(w, string(b))
-}
+		resultado := []SearchResponse{}
+		i, f := catalog.LimitesDaPagina(len(musicasRes), pagina)
+		for _, e := range encontradas[i:f] {
+			m := e.Musica
+			resultado = append(resultado, SearchResponse{
+				IDArtista:    m.IDArtista,
+				UniqueID:     m.UniqueID,
+				Genero:       m.Genero,
+				ID:           m.ID,
+				Artista:      m.Artista,
+				Nome:         m.Nome,
+				URL:          m.URL,
+				Popularidade: m.Popularidade,
+				Acordes:      m.Acordes().ToSlice(),
+				Termos:       e.Termos,
+				Score:        e.Score,
+			})
 
-func all(vs []string, f func(string) bool) bool {
-	for _, v := range vs {
-		if !f(v) {
-			return false
 		}
+		b, err := json.Marshal(comFacetas(resultado, calculaFacetas(facetas, musicasRes)))
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Write(b)
 	}
-	return true
-}
-
-var diacriticosTransformer = transform.Chain(
-	norm.NFD,
-	transform.RemoveFunc(
-		func(r rune) bool {
-			return unicode.Is(unicode.Mn, r) // Mn: nonspacing marks
-		}),
-	norm.NFC)
-
-func removerCombinantes(s string) string {
-	r, _, _ := transform.String(diacriticosTransformer, s)
-	return r
 }
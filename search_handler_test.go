@@ -0,0 +1,43 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+	"github.com/julienschmidt/httprouter"
+)
+
+// As respostas da busca, com ou sem resultados, são JSON e acessíveis de
+// qualquer origem.
+func TestSearchCabecalhos(t *testing.T) {
+	c, err := catalog.New(strings.NewReader(datasetSimilares))
+	if err != nil {
+		t.Fatal(err)
+	}
+	router := httprouter.New()
+	router.GET("/search", SearchHandler(catalog.NewRef(c)))
+	for _, q := range []struct {
+		url, corpo string
+	}{
+		{"/search?key=inexistente", "[]"},
+		{"/search?key=um", ""},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", q.url, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("%s: status %d, want 200", q.url, rec.Code)
+		}
+		if got := rec.Header().Get("Content-Type"); got != "application/json" {
+			t.Errorf("%s: Content-Type = %q, want application/json", q.url, got)
+		}
+		if got := rec.Header().Get("Access-Control-Allow-Origin"); got != "*" {
+			t.Errorf("%s: Access-Control-Allow-Origin = %q, want *", q.url, got)
+		}
+		if q.corpo != "" && rec.Body.String() != q.corpo {
+			t.Errorf("%s: corpo = %q, want %q", q.url, rec.Body.String(), q.corpo)
+		}
+	}
+}
@@ -0,0 +1,29 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+	"github.com/julienschmidt/httprouter"
+)
+
+// Lista as sequências de acordes mais tocadas no catálogo, em qualquer tom,
+// com o número de músicas e as músicas mais populares que tocam cada uma.
+// O id de uma sequência pode ser usado em /similares?sequencia=.
+// exemplo: /sequencias
+func SequenciasHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		sequencias := ref.Get().Sequencias()
+		if sequencias == nil {
+			sequencias = []*catalog.Sequencia{}
+		}
+		b, err := json.Marshal(sequencias)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
@@ -10,10 +10,13 @@ import (
 	"strings"
 	"time"
 
+	"github.com/danielfireman/ciframe-api/acorde"
+	"github.com/danielfireman/ciframe-api/catalog"
 	sets "github.com/deckarep/golang-set"
 	"github.com/julienschmidt/httprouter"
 	"github.com/newrelic/go-agent"
 	"gopkg.in/go-redis/cache.v4"
+	"gopkg.in/redis.v4"
 )
 
 type SimilaresResponse struct {
@@ -28,34 +31,19 @@ type SimilaresResponse struct {
 	URL          string        `json:"url"`
 	Diferenca    []interface{} `json:"diferenca"`
 	Intersecao   []interface{} `json:"intersecao"`
-}
-
-// PorMaiorIntersecao implementa sort.Interface for []*Musica baseado no campo Popularidade
-type PorMenorDiferenca []*SimilaresResponse
-
-func (p PorMenorDiferenca) Len() int {
-	return len(p)
-}
-func (p PorMenorDiferenca) Swap(i, j int) {
-	p[i], p[j] = p[j], p[i]
-}
-func (p PorMenorDiferenca) Less(i, j int) bool {
-	return len(p[i].Diferenca) < len(p[j].Diferenca)
-}
-
-var sequencias = map[string]int{
-	"BmGDA":   0,
-	"CGAmF":   1,
-	"EmG":     2,
-	"CA7DmG7": 3,
-	"GmF":     4,
-	"CC7FFm":  5,
+	// Semitons necessários para transpor a música para o tom da consulta
+	// (apenas no modo relativo).
+	Transposicao *int `json:"transposicao,omitempty"`
+	// Similaridade com a consulta, de acordo com a estratégia escolhida no
+	// parâmetro score.
+	Score float64 `json:"score"`
 }
 
 type Similares struct {
 	app   newrelic.Application
 	fila  chan struct{}
 	cache *cache.Codec
+	ref   *catalog.Ref
 }
 
 func (s *Similares) GetHandler() httprouter.Handle {
@@ -69,6 +57,11 @@ func (s *Similares) GetHandler() httprouter.Handle {
 		txn := s.app.StartTransaction("similares", w, r)
 		defer txn.End()
 
+		// Toda a requisição é atendida pela mesma versão do catálogo, mesmo
+		// que o dataset seja recarregado durante o processamento.
+		c := s.ref.Get()
+		cacheKey := chaveCache(c.Versao(), r.URL.RawQuery)
+
 		queryValues := r.URL.Query()
 		pagina, err := getPaginaFromRequest(r)
 		if err != nil {
@@ -76,140 +69,314 @@ func (s *Similares) GetHandler() httprouter.Handle {
 			return
 		}
 
+		// Com facetas=generos, a resposta traz também o número de músicas de
+		// cada gênero entre todas as músicas similares.
+		facetas, err := facetasFromRequest(r)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
 		// Primeiro coisa a fazer é olhar o cache.
 		var response []*SimilaresResponse
-		if err := s.cache.Get(r.URL.RawQuery, &response); err == nil && len(response) != 0 {
-			b, err := s.toBytes(r.URL.RawQuery, response, pagina)
+		var armazenada interface{} = &response
+		if facetas {
+			armazenada = &ListaComFacetas{Resultados: &response}
+		}
+		if err := s.cache.Get(cacheKey, armazenada); err == nil && len(response) != 0 {
+			// O cache já armazena a página pedida, ordenada, e as facetas.
+			b, err := json.Marshal(armazenada)
 			if err != nil {
 				log.Printf("Erro processando request [%s]: '%q'", r.URL.String(), err)
 				w.WriteHeader(http.StatusInternalServerError)
 				return
 			}
 			w.Header().Add("Access-Control-Allow-Origin", "*")
-			fmt.Fprintf(w, string(b))
+			w.Write(b)
 			return
 		}
 
-		generosABuscar := generosFromRequest(r)
-		if queryValues.Get("sequencia") != "" {
-			acordes := strings.Replace(queryValues.Get("sequencia"), ",", "", -1)
+		generosABuscar := generosFromRequest(r, c)
+		// A sequência pode ser o id de uma das sequências famosas (veja
+		// /sequencias) ou uma lista de acordes. São retornadas as músicas que
+		// tocam a sequência em qualquer tom. As sequências da coluna
+		// SEQ_FAMOSA do dataset continuam aceitas, pelo id (0 a 5) ou pelos
+		// acordes (BmGDA), retornando as músicas marcadas no dataset.
+		if sequencia := queryValues.Get("sequencia"); sequencia != "" {
+			var musicas []*catalog.Musica
+			if seq, ok := c.Sequencia(sequencia); ok {
+				musicas, err = c.PorSequencia(seq.Acordes, generosABuscar)
+			} else if id, ok := catalog.SequenciaDataset(sequencia); ok {
+				musicas = c.PorSequenciaDataset(id, generosABuscar)
+			} else {
+				musicas, err = c.PorSequencia(strings.Split(sequencia, ","), generosABuscar)
+			}
+			if err != nil {
+				escreveErro(w, http.StatusBadRequest, fmt.Sprintf("sequência inválida: %q", sequencia))
+				return
+			}
 			var response []*SimilaresResponse
-			idSeq, ok := sequencias[acordes]
-			if ok {
-				strIdSeq := strconv.Itoa(idSeq)
-				for _, m := range applyFiltro(generosABuscar) {
-					for _, seq := range m.SeqFamosas {
-						if seq == strIdSeq {
-							response = append(response, &SimilaresResponse{
-								UniqueID:     m.UniqueID,
-								IDArtista:    m.IDArtista,
-								ID:           m.ID,
-								Artista:      m.Artista,
-								Nome:         m.Nome,
-								Popularidade: m.Popularidade,
-								Acordes:      m.Acordes().ToSlice(),
-								Genero:       m.Genero,
-								URL:          m.URL,
-							})
-							break
-						}
-					}
-
-				}
-				b, err := s.toBytes(r.URL.RawQuery, response, pagina)
-				if err != nil {
-					log.Printf("Erro processando request [%s]: '%q'", r.URL.String(), err)
-					w.WriteHeader(http.StatusInternalServerError)
-					return
-				}
-				w.Header().Add("Access-Control-Allow-Origin", "*")
-				fmt.Fprintf(w, string(b))
+			for _, m := range musicas {
+				response = append(response, &SimilaresResponse{
+					UniqueID:     m.UniqueID,
+					IDArtista:    m.IDArtista,
+					ID:           m.ID,
+					Artista:      m.Artista,
+					Nome:         m.Nome,
+					Popularidade: m.Popularidade,
+					Acordes:      m.Acordes().ToSlice(),
+					Genero:       m.Genero,
+					URL:          m.URL,
+				})
+			}
+			b, err := s.toBytes(cacheKey, response, pagina, calculaFacetas(facetas, musicas))
+			if err != nil {
+				log.Printf("Erro processando request [%s]: '%q'", r.URL.String(), err)
+				w.WriteHeader(http.StatusInternalServerError)
 				return
 			}
+			w.Header().Add("Access-Control-Allow-Origin", "*")
+			w.Write(b)
+			return
 		}
 
 		// tratamento do requists
-		acordes := sets.NewSet()
+		// Os acordes podem ser comparados de três formas:
+		// - por default, grafias enarmônicas (A# e Bb, por exemplo) são
+		//   consideradas o mesmo acorde;
+		// - com enarmonico=false, os acordes só são considerados iguais quando
+		//   possuem a mesma grafia;
+		// - com modo=relativo, são comparadas as funções dos acordes em relação
+		//   ao tom de cada música (I, V7, vi), encontrando músicas com o mesmo
+		//   formato harmônico em qualquer tom. Quando a busca é feita por
+		//   acordes, o tom da consulta pode ser informado em tom. Caso
+		//   contrário, é estimado a partir dos acordes.
+		relativo := queryValues.Get("modo") == "relativo"
+		enarmonico := queryValues.Get("enarmonico") != "false"
+		var tomConsulta acorde.Tom
+		// Estrutura de cada acorde da consulta, indexada pela cifra canônica.
+		acordes := make(map[string]acorde.Acorde)
 		switch {
 		case queryValues.Get("acordes") != "":
+			// Lista de acordes, na ordem da consulta.
+			var sequencia []acorde.Acorde
 			for _, a := range strings.Split(queryValues.Get("acordes"), ",") {
-				acordes.Add(a)
+				if estrutura, err := acorde.Parse(a); err == nil {
+					acordes[estrutura.String()] = estrutura
+					sequencia = append(sequencia, estrutura)
+				}
+			}
+			if relativo {
+				if queryValues.Get("tom") != "" {
+					tomConsulta, err = acorde.ParseTom(queryValues.Get("tom"))
+				} else {
+					tomConsulta, err = estimaTom(sequencia)
+				}
+				if err != nil {
+					w.WriteHeader(http.StatusBadRequest)
+					return
+				}
 			}
 		case queryValues.Get("id_unico_musica") != "":
-			m, ok := musicasDict[queryValues.Get("id_unico_musica")]
-			if ok {
-				acordes = m.Acordes()
-			} else {
+			m, ok := c.Musica(queryValues.Get("id_unico_musica"))
+			if !ok {
 				w.WriteHeader(http.StatusBadRequest)
 				return
 			}
-		}
-		buildSegment := newrelic.StartSegment(txn, "similares_find")
-		musicasSimilares := sets.NewSet()
-		for a := range acordes.Iter() {
-			if m, ok := musicasPorAcorde[a.(string)]; ok {
-				musicasSimilares = musicasSimilares.Union(m)
+			for i, a := range m.Cifra {
+				acordes[a] = m.Estruturas[i]
 			}
-		}
-		if generosABuscar.Cardinality() > 0 {
-			porGenero := sets.NewSet()
-			for g := range generosABuscar.Iter() {
-				if m, ok := musicasPorGenero[g.(string)]; ok {
-					porGenero = porGenero.Union(m)
+			if relativo {
+				if tomConsulta, ok = m.Tonalidade(); !ok {
+					w.WriteHeader(http.StatusBadRequest)
+					return
 				}
 			}
-			musicasSimilares = musicasSimilares.Intersect(porGenero)
+		}
+		comparacao := catalog.COMPARA_ENARMONICO
+		switch {
+		case relativo:
+			comparacao = catalog.COMPARA_GRAU
+		case !enarmonico:
+			comparacao = catalog.COMPARA_GRAFIA
+		}
+		consulta := sets.NewSet()
+		for a, estrutura := range acordes {
+			switch comparacao {
+			case catalog.COMPARA_GRAU:
+				consulta.Add(estrutura.Grau(tomConsulta.Altura()))
+			case catalog.COMPARA_ENARMONICO:
+				consulta.Add(estrutura.Enarmonico().String())
+			default:
+				consulta.Add(a)
+			}
 		}
 
-		for mID := range musicasSimilares.Iter() {
-			m := musicasDict[mID.(string)]
-			mAcordesSet := m.Acordes()
-			if mAcordesSet.Cardinality() > 1 && queryValues.Get("id_unico_musica") != m.UniqueID {
-				response = append(response, &SimilaresResponse{
-					UniqueID:     m.UniqueID,
-					IDArtista:    m.IDArtista,
-					ID:           m.ID,
-					Artista:      m.Artista,
-					Nome:         m.Nome,
-					Popularidade: m.Popularidade,
-					Acordes:      mAcordesSet.ToSlice(),
-					Genero:       m.Genero,
-					URL:          m.URL,
-					Diferenca:    mAcordesSet.Difference(acordes).ToSlice(),
-					Intersecao:   mAcordesSet.Intersect(acordes).ToSlice(),
-				})
+		// Com somente_conhecidos=true, são retornadas apenas as músicas que não
+		// possuem acordes fora da consulta, admitindo até tolerancia acordes
+		// desconhecidos.
+		somenteConhecidos := queryValues.Get("somente_conhecidos") == "true"
+		tolerancia := 0
+		if queryValues.Get("tolerancia") != "" {
+			tolerancia, err = strconv.Atoi(queryValues.Get("tolerancia"))
+			if err != nil || tolerancia < 0 || tolerancia > catalog.MAX_TOLERANCIA {
+				w.WriteHeader(http.StatusBadRequest)
+				return
 			}
 		}
+
+		// Estratégia usada para calcular o score de cada música.
+		nomeScore := queryValues.Get("score")
+		if nomeScore == "" {
+			nomeScore = "diferenca"
+		}
+		score, ok := estrategiasScore[nomeScore]
+		if !ok {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		buildSegment := newrelic.StartSegment(txn, "similares_find")
+		similares := ordenaSimilares(c, catalog.Consulta{
+			Termos:            consulta,
+			Comparacao:        comparacao,
+			Generos:           generosABuscar,
+			SomenteConhecidos: somenteConhecidos,
+			Tolerancia:        tolerancia,
+			Pesos:             nomeScore == "idf",
+		}, queryValues.Get("id_unico_musica"), score)
+		var tom *acorde.Tom
+		if relativo {
+			tom = &tomConsulta
+		}
+		i, f := catalog.LimitesDaPagina(similares.Len(), pagina)
+		response = similares.respostas(i, f, consulta, comparacao, tom)
 		buildSegment.End()
-		b, err := s.toBytes(r.URL.RawQuery, response, pagina)
+		b, err := s.armazena(cacheKey, comFacetas(response, calculaFacetas(facetas, similares.musicas())))
 		if err != nil {
 			log.Printf("Erro processando request [%s]: '%q'", r.URL.String(), err)
 			w.WriteHeader(http.StatusInternalServerError)
 			return
 		}
 		w.Header().Add("Access-Control-Allow-Origin", "*")
-		fmt.Fprintf(w, string(b))
+		w.Write(b)
 	}
 }
 
-func (s *Similares) toBytes(cacheKey string, response []*SimilaresResponse, pagina int) ([]byte, error) {
+func (s *Similares) toBytes(cacheKey string, response []*SimilaresResponse, pagina int, facetas *Facetas) ([]byte, error) {
 	// Para retornar, primeiro ordenamos
-	sort.Sort(PorMenorDiferenca(response))
+	sort.Sort(PorScore(response))
 
 	// Consideramos os limites da página.
-	i, f := limitesDaPagina(len(response), pagina)
+	i, f := catalog.LimitesDaPagina(len(response), pagina)
+	return s.armazena(cacheKey, comFacetas(response[i:f], facetas))
+}
 
-	// Colocamos no cache.
+// armazena coloca no cache a página de respostas, já ordenada (acompanhada
+// das facetas, caso tenham sido pedidas), e a converte para JSON.
+func (s *Similares) armazena(cacheKey string, response interface{}) ([]byte, error) {
 	s.cache.Set(&cache.Item{
 		Key:        cacheKey,
-		Object:     response[i:f],
+		Object:     response,
 		Expiration: 6 * time.Hour,
 	})
-
-	// Convertemos para JSON.
-	b, err := json.Marshal(response[i:f])
+	b, err := json.Marshal(response)
 	if err != nil {
 		return nil, err
 	}
 	return b, nil
 }
+
+// ordenaSimilares compara a consulta com as músicas do catálogo, exceto a
+// música excluida, e as ordena pelo score.
+func ordenaSimilares(c *catalog.Catalog, q catalog.Consulta, excluida string, score estrategiaScore) porScore {
+	// A comparação é feita sobre os bitsets do catálogo. As respostas, que
+	// precisam dos acordes de cada música, só são construídas para a página
+	// pedida.
+	var similares porScore
+	for _, sim := range c.Similares(q) {
+		if sim.Musica.UniqueID == excluida {
+			continue
+		}
+		similares.similares = append(similares.similares, sim)
+		similares.scores = append(similares.scores, score(q.Termos.Cardinality(), sim))
+	}
+	sort.Sort(similares)
+	return similares
+}
+
+// musicas retorna todas as músicas similares, na ordem do score.
+func (p porScore) musicas() []*catalog.Musica {
+	musicas := make([]*catalog.Musica, len(p.similares))
+	for i, sim := range p.similares {
+		musicas[i] = sim.Musica
+	}
+	return musicas
+}
+
+// respostas constrói as respostas das músicas similares [i, f). Caso tom não
+// seja nulo (comparação por graus), as respostas trazem a transposição das
+// músicas para o tom.
+func (p porScore) respostas(i, f int, consulta sets.Set, comparacao int, tom *acorde.Tom) []*SimilaresResponse {
+	var response []*SimilaresResponse
+	for j := i; j < f; j++ {
+		m := p.similares[j].Musica
+		// A resposta mantém a grafia usada na cifra de cada música.
+		mAcordesSet := m.Acordes()
+		diferenca, intersecao := sets.NewSet(), sets.NewSet()
+		for a := range mAcordesSet.Iter() {
+			if consulta.Contains(m.Termo(comparacao, a.(string))) {
+				intersecao.Add(a)
+			} else {
+				diferenca.Add(a)
+			}
+		}
+		var transposicao *int
+		if tom != nil {
+			tomMusica, _ := m.Tonalidade()
+			semitons := acorde.Intervalo(tomMusica.Altura(), tom.Altura())
+			transposicao = &semitons
+		}
+		response = append(response, &SimilaresResponse{
+			UniqueID:     m.UniqueID,
+			IDArtista:    m.IDArtista,
+			ID:           m.ID,
+			Artista:      m.Artista,
+			Nome:         m.Nome,
+			Popularidade: m.Popularidade,
+			Acordes:      mAcordesSet.ToSlice(),
+			Genero:       m.Genero,
+			URL:          m.URL,
+			Diferenca:    diferenca.ToSlice(),
+			Intersecao:   intersecao.ToSlice(),
+			Transposicao: transposicao,
+			Score:        p.scores[j],
+		})
+	}
+	return response
+}
+
+// chaveCache retorna a chave usada para armazenar no cache a resposta de uma
+// consulta. A chave inclui a versão do catálogo, de forma que respostas
+// calculadas com um dataset antigo não sejam reutilizadas.
+func chaveCache(versao, query string) string {
+	return fmt.Sprintf("similares:%s:%s", versao, query)
+}
+
+// InvalidaCache remove do Redis as respostas calculadas com a versão do
+// catálogo passada.
+func (s *Similares) InvalidaCache(versao string) error {
+	scanner, ok := s.cache.Redis.(interface {
+		Scan(cursor uint64, match string, count int64) redis.Scanner
+	})
+	if !ok {
+		return fmt.Errorf("cliente Redis não suporta SCAN")
+	}
+	it := scanner.Scan(0, chaveCache(versao, "*"), 1000).Iterator()
+	for it.Next() {
+		if err := s.cache.Delete(it.Val()); err != nil && err != cache.ErrCacheMiss {
+			return err
+		}
+	}
+	return it.Err()
+}
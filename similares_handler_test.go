@@ -0,0 +1,127 @@
+package main
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"sort"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+	"github.com/julienschmidt/httprouter"
+	"github.com/newrelic/go-agent"
+	"gopkg.in/go-redis/cache.v4"
+	"gopkg.in/redis.v4"
+)
+
+const datasetSimilares = `ARTISTA_ID,MUSICA_ID,ARTISTA,MUSICA,GENERO,POPULARIDADE,TOM,SEQ_FAMOSA,CIFRA
+a,um,A,Um,Rock,300,C,1,C;G;Am;F
+a,dois,A,Dois,Rock,200,D,NA,D;A;Bm;G
+b,tres,B,Tres,Samba,100,G,0,Bm;G;D;A
+`
+
+// redisMemoria guarda o cache das respostas em memória.
+type redisMemoria map[string][]byte
+
+func (r redisMemoria) Set(key string, value interface{}, expiration time.Duration) *redis.StatusCmd {
+	r[key] = value.([]byte)
+	return redis.NewStatusResult("OK", nil)
+}
+
+func (r redisMemoria) Get(key string) *redis.StringCmd {
+	v, ok := r[key]
+	if !ok {
+		return redis.NewStringResult(nil, redis.Nil)
+	}
+	return redis.NewStringResult(v, nil)
+}
+
+func (r redisMemoria) Del(keys ...string) *redis.IntCmd {
+	for _, k := range keys {
+		delete(r, k)
+	}
+	return redis.NewIntResult(int64(len(keys)), nil)
+}
+
+// appTeste retorna uma aplicação do New Relic que não envia dados.
+func appTeste(t *testing.T) newrelic.Application {
+	t.Helper()
+	cfg := newrelic.NewConfig("ciframe-api-teste", strings.Repeat("0", 40))
+	cfg.Enabled = false
+	app, err := newrelic.NewApplication(cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return app
+}
+
+func similaresTeste(t *testing.T) http.Handler {
+	t.Helper()
+	c, err := catalog.New(strings.NewReader(datasetSimilares))
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Similares{
+		app:   appTeste(t),
+		fila:  make(chan struct{}, 1),
+		cache: &cache.Codec{Redis: redisMemoria{}, Marshal: json.Marshal, Unmarshal: json.Unmarshal},
+		ref:   catalog.NewRef(c),
+	}
+	router := httprouter.New()
+	router.GET("/similares", s.GetHandler())
+	return router
+}
+
+func TestSimilaresPorSequencia(t *testing.T) {
+	router := similaresTeste(t)
+	for _, c := range []struct {
+		sequencia string
+		want      []string
+	}{
+		// Sequência minerada do catálogo, tocada em C e em D.
+		{"I-V-vi-IV", []string{"dois", "um"}},
+		// Sequências da coluna SEQ_FAMOSA, pelos acordes e pelo id.
+		{"BmGDA", []string{"tres"}},
+		{"Bm,G,D,A", []string{"tres"}},
+		{"0", []string{"tres"}},
+		{"CGAmF", []string{"um"}},
+		// Lista de acordes, encontrada em qualquer tom.
+		{"E,B,C%23m,A", []string{"dois", "um"}},
+		{"G,D", []string{"dois", "tres", "um"}},
+	} {
+		rec := httptest.NewRecorder()
+		router.ServeHTTP(rec, httptest.NewRequest("GET", "/similares?sequencia="+c.sequencia, nil))
+		if rec.Code != http.StatusOK {
+			t.Errorf("sequencia=%s: status %d, want 200", c.sequencia, rec.Code)
+			continue
+		}
+		var response []SimilaresResponse
+		if err := json.Unmarshal(rec.Body.Bytes(), &response); err != nil {
+			t.Errorf("sequencia=%s: %v", c.sequencia, err)
+			continue
+		}
+		var got []string
+		for _, r := range response {
+			got = append(got, r.ID)
+		}
+		sort.Strings(got)
+		if !reflect.DeepEqual(got, c.want) {
+			t.Errorf("sequencia=%s = %v, want %v", c.sequencia, got, c.want)
+		}
+	}
+}
+
+func TestSimilaresSequenciaInvalida(t *testing.T) {
+	rec := httptest.NewRecorder()
+	similaresTeste(t).ServeHTTP(rec, httptest.NewRequest("GET", "/similares?sequencia=C,X", nil))
+	if rec.Code != http.StatusBadRequest {
+		t.Fatalf("status %d, want 400", rec.Code)
+	}
+	var erro ErroResponse
+	if err := json.Unmarshal(rec.Body.Bytes(), &erro); err != nil {
+		t.Errorf("corpo %q não é um erro JSON: %v", rec.Body.String(), err)
+	}
+}
@@ -0,0 +1,69 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	"github.com/julienschmidt/httprouter"
+)
+
+type TomRequest struct {
+	Acordes []string `json:"acordes"`
+}
+
+type TomResponse struct {
+	Tom              acorde.Tom          `json:"tom"`
+	Confianca        float64             `json:"confianca"`
+	Candidatos       []acorde.Estimativa `json:"candidatos"`
+	AcordesInvalidos []string            `json:"acordes_invalidos,omitempty"`
+}
+
+// Número de tons candidatos retornados, além do mais provável.
+const NUM_CANDIDATOS_TOM = 5
+
+// Estima o tom de uma sequência de acordes, passada na ordem em que são
+// tocados (o primeiro e o último acordes são considerados na estimativa).
+// exemplo: POST /tom {"acordes": ["C", "G", "Am", "F", "C"]}
+func TomHandler(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+	var req TomRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	var resposta TomResponse
+	var cifra []acorde.Acorde
+	for _, a := range req.Acordes {
+		estrutura, err := acorde.Parse(a)
+		if err != nil {
+			resposta.AcordesInvalidos = append(resposta.AcordesInvalidos, a)
+			continue
+		}
+		cifra = append(cifra, estrutura)
+	}
+	tom, confianca, ok := acorde.EstimaTom(cifra)
+	if !ok {
+		w.WriteHeader(http.StatusBadRequest)
+		return
+	}
+	resposta.Tom = tom
+	resposta.Confianca = confianca
+	resposta.Candidatos = acorde.EstimaTons(cifra)[:NUM_CANDIDATOS_TOM]
+	b, err := json.Marshal(resposta)
+	if err != nil {
+		w.WriteHeader(http.StatusInternalServerError)
+		return
+	}
+	w.Header().Add("Access-Control-Allow-Origin", "*")
+	w.Write(b)
+}
+
+// estimaTom estima o tom de uma sequência de acordes.
+func estimaTom(cifra []acorde.Acorde) (acorde.Tom, error) {
+	tom, _, ok := acorde.EstimaTom(cifra)
+	if !ok {
+		return acorde.Tom{}, fmt.Errorf("não foi possível estimar o tom de %v", cifra)
+	}
+	return tom, nil
+}
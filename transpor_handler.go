@@ -0,0 +1,117 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+
+	"github.com/danielfireman/ciframe-api/acorde"
+	"github.com/danielfireman/ciframe-api/catalog"
+	sets "github.com/deckarep/golang-set"
+	"github.com/julienschmidt/httprouter"
+)
+
+type TransporResponse struct {
+	UniqueID    string        `json:"id_unico_musica"`
+	Nome        string        `json:"nome_musica"`
+	Artista     string        `json:"nome_artista"`
+	TomOriginal string        `json:"tom_original"` // tom do dataset ou, caso ausente, o tom estimado.
+	Tom         string        `json:"tom"`
+	Semitons    int           `json:"semitons"`
+	Cifra       []string      `json:"cifra"`
+	Acordes     []interface{} `json:"acordes"`
+}
+
+// Retorna a cifra da música transposta para outro tom.
+// params: tom ou semitons. Cada acorde mantém a grafia do seu intervalo em
+// relação à tônica (A7/C# em A resulta em Eb7/G em Eb), de forma que a
+// grafia segue o tom passado (Eb ou D#) ou, com semitons, a tônica usual do
+// tom resultante. O modo (maior ou menor) da música é mantido.
+// exemplo 1: /musica/legiao-urbana_tempo-perdido/transpor?tom=D
+// exemplo 2: /musica/legiao-urbana_tempo-perdido/transpor?semitons=-2
+func TransporHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, p httprouter.Params) {
+		c := ref.Get()
+		m, ok := c.Musica(p.ByName("id"))
+		if !ok {
+			escreveErro(w, http.StatusNotFound, fmt.Sprintf("música não encontrada: %q", p.ByName("id")))
+			return
+		}
+		queryValues := r.URL.Query()
+		tomOriginal, temTom := m.Tonalidade()
+
+		var semitons int
+		var tom acorde.Tom
+		switch {
+		case queryValues.Get("tom") != "":
+			destino, err := acorde.ParseTom(queryValues.Get("tom"))
+			if err != nil || !temTom {
+				// Sem o tom original não é possível calcular a distância.
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			semitons = acorde.Intervalo(tomOriginal.Altura(), destino.Altura())
+			tom = acorde.Tom{Tonica: destino.Tonica, Menor: tomOriginal.Menor}
+		case queryValues.Get("semitons") != "":
+			var err error
+			semitons, err = strconv.Atoi(queryValues.Get("semitons"))
+			if err != nil {
+				w.WriteHeader(http.StatusBadRequest)
+				return
+			}
+			if temTom {
+				tom = tomOriginal.Transpoe(semitons)
+			}
+		default:
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		resposta := TransporResponse{
+			UniqueID:    m.UniqueID,
+			Nome:        m.Nome,
+			Artista:     m.Artista,
+			TomOriginal: tomOriginal.String(),
+			Semitons:    semitons,
+		}
+		if temTom {
+			resposta.Tom = tom.String()
+			resposta.Cifra = transpoeCifraNoTom(m.Estruturas, tomOriginal, tom)
+		} else {
+			resposta.Cifra = transpoeCifra(m.Estruturas, semitons)
+		}
+		acordes := sets.NewSet()
+		for _, a := range resposta.Cifra {
+			acordes.Add(a)
+		}
+		resposta.Acordes = acordes.ToSlice()
+		b, err := json.Marshal(resposta)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
+
+// transpoeCifra transpõe a cifra de uma música de tom desconhecido,
+// grafando os acordes com sustenidos.
+func transpoeCifra(cifra []acorde.Acorde, semitons int) []string {
+	transposta := make([]string, 0, len(cifra))
+	for _, a := range cifra {
+		transposta = append(transposta, a.Transpoe(semitons, false).String())
+	}
+	return transposta
+}
+
+// transpoeCifraNoTom transpõe a cifra do tom de para o tom para, grafando
+// cada acorde pelo seu intervalo em relação à tônica.
+func transpoeCifraNoTom(cifra []acorde.Acorde, de, para acorde.Tom) []string {
+	transposta := make([]string, 0, len(cifra))
+	for _, a := range cifra {
+		transposta = append(transposta, a.TranspoeNoTom(de, para).String())
+	}
+	return transposta
+}
@@ -0,0 +1,74 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+	"github.com/julienschmidt/httprouter"
+)
+
+type TrilhaResponse struct {
+	UniqueID string          `json:"id_unico_musica"`
+	Artista  string          `json:"nome_artista"`
+	Nome     string          `json:"nome_musica"`
+	Etapas   []EtapaResponse `json:"etapas"`
+}
+
+type EtapaResponse struct {
+	Acordes []string         `json:"acordes_novos"`
+	Musicas []MusicaResponse `json:"musicas_para_praticar"`
+}
+
+type MusicaResponse struct {
+	UniqueID     string `json:"id_unico_musica"`
+	Artista      string `json:"nome_artista"`
+	Nome         string `json:"nome_musica"`
+	Popularidade int    `json:"popularidade"`
+	URL          string `json:"url"`
+}
+
+// Gera uma trilha de aprendizado até a música alvo: uma sequência de etapas,
+// cada uma com um ou dois acordes novos e músicas populares para praticar
+// que usam apenas os acordes aprendidos até a etapa.
+// params: id_unico_musica e acordes (opcional), os acordes já conhecidos.
+// exemplo: /trilha?id_unico_musica=legiao-urbana_tempo-perdido&acordes=C,G
+func TrilhaHandler(ref *catalog.Ref) httprouter.Handle {
+	return func(w http.ResponseWriter, r *http.Request, _ httprouter.Params) {
+		c := ref.Get()
+		alvo, ok := c.Musica(r.URL.Query().Get("id_unico_musica"))
+		if !ok {
+			escreveErro(w, http.StatusNotFound, fmt.Sprintf("música não encontrada: %q", r.URL.Query().Get("id_unico_musica")))
+			return
+		}
+		conhecidos, _ := acordesFromRequest(r)
+
+		resposta := TrilhaResponse{
+			UniqueID: alvo.UniqueID,
+			Artista:  alvo.Artista,
+			Nome:     alvo.Nome,
+			Etapas:   []EtapaResponse{},
+		}
+		for _, etapa := range c.Trilha(alvo, conhecidos) {
+			e := EtapaResponse{Acordes: etapa.Acordes, Musicas: []MusicaResponse{}}
+			for _, m := range etapa.Musicas {
+				e.Musicas = append(e.Musicas, MusicaResponse{
+					UniqueID:     m.UniqueID,
+					Artista:      m.Artista,
+					Nome:         m.Nome,
+					Popularidade: m.Popularidade,
+					URL:          m.URL,
+				})
+			}
+			resposta.Etapas = append(resposta.Etapas, e)
+		}
+		b, err := json.Marshal(resposta)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		w.Header().Add("Access-Control-Allow-Origin", "*")
+		w.Write(b)
+	}
+}
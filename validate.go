@@ -0,0 +1,58 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"sort"
+
+	"github.com/danielfireman/ciframe-api/catalog"
+	sets "github.com/deckarep/golang-set"
+)
+
+// validate implementa o subcomando "validate", que verifica a qualidade do
+// dataset antes de um deploy. Retorna o código de saída do processo: 0 caso
+// nenhum problema seja encontrado e 1 caso contrário.
+// exemplo: ciframe-api validate -dataset data/dataset_final.csv
+func validate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ContinueOnError)
+	dataset := fs.String("dataset", DATASET, "caminho do dataset a ser validado")
+	if err := fs.Parse(args); err != nil {
+		return 2
+	}
+
+	f, err := os.Open(*dataset)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+	defer f.Close()
+
+	seqIDs := sets.NewSet()
+	for id := range catalog.SequenciasDataset {
+		seqIDs.Add(id)
+	}
+	relatorio, err := catalog.Valida(f, seqIDs)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 2
+	}
+
+	for _, p := range relatorio.Problemas {
+		fmt.Println(p)
+	}
+	porTipo := relatorio.PorTipo()
+	var tipos []string
+	for t := range porTipo {
+		tipos = append(tipos, t)
+	}
+	sort.Strings(tipos)
+	fmt.Printf("\n%d músicas lidas, %d problemas encontrados.\n", relatorio.Musicas, len(relatorio.Problemas))
+	for _, t := range tipos {
+		fmt.Printf("%s: %d\n", t, porTipo[t])
+	}
+	if len(relatorio.Problemas) > 0 {
+		return 1
+	}
+	return 0
+}